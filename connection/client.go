@@ -19,6 +19,8 @@ type Client struct {
 	Database *mongo.Database
 	// Models stores model instances associated with this connection
 	Models map[string]interface{}
+	// RefModels stores the models references are resolved with when populating
+	RefModels map[string]interface{}
 	// Name of this connection instance
 	Name string
 }
@@ -48,6 +50,7 @@ func Connect(uri, dbName string) (*Client, error) {
 		MongoClient: client,
 		Database:    client.Database(dbName),
 		Models:      make(map[string]interface{}),
+		RefModels:   make(map[string]interface{}),
 	}, nil
 }
 
@@ -121,3 +124,16 @@ func (c *Client) GetModel(name string) interface{} {
 	}
 	return model
 }
+
+// RegisterRefModel registers the model references to the given name are populated with
+func (c *Client) RegisterRefModel(name string, model interface{}) {
+	if c.RefModels == nil {
+		c.RefModels = make(map[string]interface{})
+	}
+	c.RefModels[name] = model
+}
+
+// GetRefModel retrieves the model references to the given name are populated with
+func (c *Client) GetRefModel(name string) interface{} {
+	return c.RefModels[name]
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IndexedError is the error of a single item of a batch operation
+type IndexedError struct {
+	// Index is the position of the item in the batch
+	Index int
+	// Err is the error the item failed with
+	Err error
+}
+
+// Error returns the item's error prefixed with its index
+func (e IndexedError) Error() string {
+	return fmt.Sprintf("[%d] %v", e.Index, e.Err)
+}
+
+// Unwrap returns the item's error
+func (e IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the items of a batch operation that failed.
+// errors.Is matches any of the item errors, e.g. IsValidationError reports
+// whether at least one item failed validation.
+type BatchError struct {
+	Errors []IndexedError
+}
+
+// Error summarizes the failed items
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, itemErr := range e.Errors {
+		messages[i] = itemErr.Error()
+	}
+	return fmt.Sprintf("%d item(s) failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the item errors
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, itemErr := range e.Errors {
+		errs[i] = itemErr
+	}
+	return errs
+}
+
+// Indexes returns the indexes of the failed items
+func (e *BatchError) Indexes() []int {
+	indexes := make([]int, len(e.Errors))
+	for i, itemErr := range e.Errors {
+		indexes[i] = itemErr.Index
+	}
+	return indexes
+}
+
+// Add records the error of an item
+func (e *BatchError) Add(index int, err error) {
+	e.Errors = append(e.Errors, IndexedError{Index: index, Err: err})
+}
+
+// ErrOrNil returns the batch error if any item failed, nil otherwise
+func (e *BatchError) ErrOrNil() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AsBatchError returns the BatchError an error is or wraps, if any
+func AsBatchError(err error) (*BatchError, bool) {
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr, true
+	}
+	return nil, false
+}
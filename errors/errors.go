@@ -31,6 +31,12 @@ var (
 
 	// ErrDecoding indicates an error decoding documents
 	ErrDecoding = errors.New("failed to decode documents")
+
+	// ErrVersionConflict indicates a document was changed by another writer since it was read
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrMigrationLocked indicates migrations are being run by another process
+	ErrMigrationLocked = errors.New("migrations are locked")
 )
 
 // WithDetails adds detailed information to a standard error
@@ -46,6 +46,16 @@ func IsDecodingError(err error) bool {
 	return errors.Is(err, ErrDecoding)
 }
 
+// IsVersionConflict checks if an error is or wraps ErrVersionConflict
+func IsVersionConflict(err error) bool {
+	return errors.Is(err, ErrVersionConflict)
+}
+
+// IsMigrationLocked checks if an error is or wraps ErrMigrationLocked
+func IsMigrationLocked(err error) bool {
+	return errors.Is(err, ErrMigrationLocked)
+}
+
 // IsSchemaValidationError checks specifically for schema validation errors
 func IsSchemaValidationError(err error) bool {
 	// First check if it's a validation error at all
@@ -128,6 +138,10 @@ func FormatError(err error) string {
 		errType = "Connection"
 	case IsDecodingError(err):
 		errType = "Decoding"
+	case IsVersionConflict(err):
+		errType = "VersionConflict"
+	case IsMigrationLocked(err):
+		errType = "MigrationLocked"
 	default:
 		errType = "Unknown"
 	}
@@ -140,6 +154,8 @@ type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// Fields lists the failed fields of a validation error
+	Fields []FieldError `json:"fields,omitempty"`
 }
 
 // ToErrorResponse converts an error to a structured response
@@ -179,6 +195,12 @@ func ToErrorResponse(err error) ErrorResponse {
 	case IsDecodingError(err):
 		code = "decoding_error"
 		message = "Failed to decode data"
+	case IsVersionConflict(err):
+		code = "version_conflict"
+		message = "Resource was modified by another request"
+	case IsMigrationLocked(err):
+		code = "migration_locked"
+		message = "Migrations are being run by another process"
 	default:
 		code = "unknown_error"
 		message = "An unexpected error occurred"
@@ -189,14 +211,19 @@ func ToErrorResponse(err error) ErrorResponse {
 	for _, baseErr := range []error{
 		ErrNotFound, ErrInvalidObjectID, ErrValidation,
 		ErrMiddleware, ErrNilCollection, ErrDatabase,
-		ErrConnection, ErrDecoding,
+		ErrConnection, ErrDecoding, ErrVersionConflict,
+		ErrMigrationLocked,
 	} {
 		details = strings.Replace(details, baseErr.Error()+": ", "", 1)
 	}
 
-	return ErrorResponse{
+	response := ErrorResponse{
 		Code:    code,
 		Message: message,
 		Details: details,
 	}
+	if validationErr, ok := AsValidationError(err); ok {
+		response.Fields = validationErr.Errors
+	}
+	return response
 }
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError describes a single validation failure of a document field
+type FieldError struct {
+	// Path is the dotted path of the field, e.g. "address.zip" or "items.1.sku"
+	Path string `json:"path"`
+	// Rule is the rule the field failed, e.g. "required", "type", "min", "maxItems", "enum", "format" or "custom"
+	Rule string `json:"rule"`
+	// Value is the offending value, nil when the field is missing
+	Value interface{} `json:"value,omitempty"`
+	// Message describes the failure
+	Message string `json:"message"`
+}
+
+// Error returns the failure message
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError lists every field of a document that failed validation.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error joins the messages of the failed fields
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Add records a failed field
+func (e *ValidationError) Add(path, rule string, value interface{}, message string) {
+	e.Errors = append(e.Errors, FieldError{Path: path, Rule: rule, Value: value, Message: message})
+}
+
+// Paths returns the paths of the failed fields
+func (e *ValidationError) Paths() []string {
+	paths := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		paths[i] = fieldErr.Path
+	}
+	return paths
+}
+
+// ErrOrNil returns the validation error if any field failed, nil otherwise
+func (e *ValidationError) ErrOrNil() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AsValidationError returns the ValidationError an error is or wraps, if any
+func AsValidationError(err error) (*ValidationError, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
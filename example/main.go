@@ -105,7 +105,8 @@ func basicExample() {
 
 	// Create a model using the generic model
 	userModel := merhongo.ModelNew[User]("User", userSchema, merhongo.ModelOptions{
-		Database: client.Database,
+		Database:          client.Database,
+		AutoCreateIndexes: true,
 	})
 
 	// Create a new user
@@ -197,11 +198,13 @@ func multiConnectionExample() {
 
 	// Create models with specific connections using connection names
 	userModel := merhongo.ModelNew[User]("User", userSchema, merhongo.ModelOptions{
-		ConnectionName: "users",
+		ConnectionName:    "users",
+		AutoCreateIndexes: true,
 	})
 
 	productModel := merhongo.ModelNew[Product]("Product", productSchema, merhongo.ModelOptions{
-		ConnectionName: "products",
+		ConnectionName:    "products",
+		AutoCreateIndexes: true,
 	})
 
 	// Use models with different connections
@@ -383,7 +386,8 @@ func schemaFromStructExample() {
 
 	// Create a model with the generated schema
 	userModel := merhongo.ModelNew[User]("StructUser", userSchema, merhongo.ModelOptions{
-		Database: client.Database,
+		Database:          client.Database,
+		AutoCreateIndexes: true,
 	})
 
 	// Create a new user
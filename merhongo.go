@@ -115,7 +115,8 @@ type ModelOptions struct {
 	Database *mongo.Database
 	// ConnectionName specifies a named connection to use if Database is nil
 	ConnectionName string
-	// AutoCreateIndexes determines if indexes should be created automatically
+	// AutoCreateIndexes creates the missing indexes the schema declares with the model.
+	// It is enabled when no options are given; otherwise call Model.SyncIndexes.
 	AutoCreateIndexes bool
 	// CustomValidator can override the default document validator
 	CustomValidator func(interface{}) error
@@ -155,7 +156,7 @@ func ModelNew[T any](name string, schema *schema.Schema, options ...ModelOptions
 	}
 
 	// Create the generic model
-	m := model.NewGeneric[T](name, schema, db)
+	m := model.NewGeneric[T](name, schema, db, model.Options{SkipIndexes: !opts.AutoCreateIndexes})
 
 	// Apply custom validator if provided
 	if opts.CustomValidator != nil && m.Schema != nil {
@@ -172,6 +173,9 @@ func ModelNew[T any](name string, schema *schema.Schema, options ...ModelOptions
 		for _, client := range connections {
 			if client.Database == db {
 				client.RegisterModel(name, &modelType)
+				// Register the model itself too, so other models can reference it
+				client.RegisterRefModel(name, m)
+				m.Client = client
 				break
 			}
 		}
@@ -185,3 +189,15 @@ func ModelNew[T any](name string, schema *schema.Schema, options ...ModelOptions
 func QueryNew() *query.Builder {
 	return query.New()
 }
+
+// UpdateNew is a convenience function to create a new update builder.
+// It's a simple wrapper around query.Update.
+func UpdateNew() *query.UpdateBuilder {
+	return query.Update()
+}
+
+// PipelineNew is a convenience function to create a new aggregation pipeline builder.
+// It's a simple wrapper around query.NewPipeline.
+func PipelineNew() *query.Pipeline {
+	return query.NewPipeline()
+}
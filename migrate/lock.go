@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lockID is the _id of the lock document in the migrations collection
+const lockID = "lock"
+
+// lockDocument is held by the process running migrations
+type lockDocument struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	LockedAt  time.Time `bson:"lockedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// heldLock is the migration lock held by this process while migrations run
+type heldLock struct {
+	migrator *Migrator
+	owner    string
+	// lost cancels the context of the migrations when the lock is taken over
+	lost context.CancelCauseFunc
+	stop chan struct{}
+	done chan struct{}
+}
+
+// lock takes the migration lock. The lock is a document whose unique _id lets a single
+// process insert it; a lock whose expiry passed is taken over. While it is held its
+// expiry is pushed back every third of the TTL, and the returned context is canceled
+// if it is taken over anyway. It fails with ErrMigrationLocked while another process
+// holds it.
+func (m *Migrator) lock(ctx context.Context) (context.Context, *heldLock, error) {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), primitive.NewObjectID().Hex())
+
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now().UTC()
+		_, err := m.Collection.InsertOne(ctx, lockDocument{
+			ID:        lockID,
+			Owner:     owner,
+			LockedAt:  now,
+			ExpiresAt: now.Add(m.lockTTL),
+		})
+		if err == nil {
+			lockCtx, lost := context.WithCancelCause(ctx)
+			l := &heldLock{
+				migrator: m,
+				owner:    owner,
+				lost:     lost,
+				stop:     make(chan struct{}),
+				done:     make(chan struct{}),
+			}
+			go l.keepAlive(lockCtx)
+			return lockCtx, l, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, nil, errors.Wrap(errors.ErrDatabase, err.Error())
+		}
+
+		// Take over a lock left behind by a process that died while holding it
+		result, err := m.Collection.DeleteOne(ctx, bson.M{"_id": lockID, "expiresAt": bson.M{"$lt": now}})
+		if err != nil {
+			return nil, nil, errors.Wrap(errors.ErrDatabase, err.Error())
+		}
+		if result.DeletedCount == 0 {
+			break
+		}
+		log.Printf("⚠️ Took over expired migration lock")
+	}
+
+	var held lockDocument
+	if err := m.Collection.FindOne(ctx, bson.M{"_id": lockID}).Decode(&held); err != nil {
+		return nil, nil, errors.ErrMigrationLocked
+	}
+	return nil, nil, errors.WithDetails(errors.ErrMigrationLocked,
+		fmt.Sprintf("held by %s since %s", held.Owner, held.LockedAt.Format(time.RFC3339)))
+}
+
+// keepAlive renews the lock every third of its TTL until it is released or lost
+func (l *heldLock) keepAlive(ctx context.Context) {
+	defer close(l.done)
+
+	interval := l.migrator.lockTTL / 3
+	if interval <= 0 {
+		interval = l.migrator.lockTTL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.renew(ctx); errors.IsMigrationLocked(err) {
+				return
+			} else if err != nil {
+				// The lock stays held until it expires, so the next renewal may still succeed
+				log.Printf("⚠️ Failed to renew migration lock: %v", err)
+			}
+		}
+	}
+}
+
+// renew pushes back the expiry of the lock. When the lock was taken over by another
+// process, it cancels the context of the migrations and fails with ErrMigrationLocked.
+func (l *heldLock) renew(ctx context.Context) error {
+	result, err := l.migrator.Collection.UpdateOne(ctx,
+		bson.M{"_id": lockID, "owner": l.owner},
+		bson.M{"$set": bson.M{"expiresAt": time.Now().UTC().Add(l.migrator.lockTTL)}})
+	if err != nil {
+		return errors.Wrap(errors.ErrDatabase, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		err := errors.WithDetails(errors.ErrMigrationLocked, "the lock was taken over by another process")
+		log.Printf("⚠️ Lost migration lock, aborting migrations")
+		l.lost(err)
+		return err
+	}
+	return nil
+}
+
+// release stops renewing the lock and releases it if the process still holds it. It
+// doesn't use the context of the migrations, which may be canceled by then.
+func (l *heldLock) release() {
+	close(l.stop)
+	<-l.done
+	l.lost(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := l.migrator.Collection.DeleteOne(ctx, bson.M{"_id": lockID, "owner": l.owner}); err != nil {
+		log.Printf("⚠️ Failed to release migration lock: %v", err)
+	}
+}
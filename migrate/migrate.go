@@ -0,0 +1,429 @@
+// Package migrate runs versioned migrations that evolve the data of a database
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/isimtekin/merhongo/connection"
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrationsCollection is the default collection recording applied migrations
+const MigrationsCollection = "_merhongo_migrations"
+
+// Func is a migration step. Inside a transaction ctx is the mongo.SessionContext of
+// the transaction, so operations must be given ctx to take part in it.
+type Func func(ctx context.Context, client *connection.Client) error
+
+// Migration is a versioned change of the database
+type Migration struct {
+	// Version orders the migrations; each migration needs a distinct positive version,
+	// e.g. 1, 2, 3 or a timestamp like 20250101120000
+	Version     int64
+	Description string
+	// Up applies the migration
+	Up Func
+	// Down reverts the migration; migrations without it can't be reverted
+	Down Func
+	// Transactional runs each step and its record in a transaction when the server
+	// supports transactions, i.e. on a replica set or sharded cluster
+	Transactional bool
+}
+
+// Options configures a Migrator
+type Options struct {
+	// Collection recording applied migrations, MigrationsCollection by default
+	Collection string
+	// LockTTL is how long the lock is held without being renewed before another process
+	// may take it over, in case the process holding it died. The process running the
+	// migrations renews it every third of the TTL. Defaults to 10 minutes.
+	LockTTL time.Duration
+}
+
+// RunOptions configures Up and Down
+type RunOptions struct {
+	// Steps limits the number of migrations run. Up applies every pending migration
+	// and Down reverts the last applied migration when Steps is zero.
+	Steps int
+	// DryRun lists the migrations that would run without running them or taking the lock
+	DryRun bool
+}
+
+// Status describes a migration and whether it was applied
+type Status struct {
+	Version     int64
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+	// Registered is false for migrations recorded as applied that aren't registered
+	Registered bool
+}
+
+// record is the document stored for an applied migration
+type record struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// Migrator applies and reverts the registered migrations of a database
+type Migrator struct {
+	Client     *connection.Client
+	Collection *mongo.Collection
+	lockTTL    time.Duration
+	migrations map[int64]Migration
+}
+
+// New creates a Migrator recording migrations in the client's database
+func New(client *connection.Client, opts ...Options) *Migrator {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Collection == "" {
+		o.Collection = MigrationsCollection
+	}
+	if o.LockTTL <= 0 {
+		o.LockTTL = 10 * time.Minute
+	}
+
+	m := &Migrator{
+		Client:     client,
+		lockTTL:    o.LockTTL,
+		migrations: make(map[int64]Migration),
+	}
+	if client != nil && client.Database != nil {
+		m.Collection = client.Database.Collection(o.Collection)
+	}
+	return m
+}
+
+// Register adds migrations to the migrator. Versions must be positive and unique,
+// and every migration needs an Up step.
+func (m *Migrator) Register(migrations ...Migration) error {
+	for _, migration := range migrations {
+		if migration.Version <= 0 {
+			return errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("migration version must be positive, got %d", migration.Version))
+		}
+		if migration.Up == nil {
+			return errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("migration %d has no up step", migration.Version))
+		}
+		if _, exists := m.migrations[migration.Version]; exists {
+			return errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("migration %d is already registered", migration.Version))
+		}
+		m.migrations[migration.Version] = migration
+	}
+	return nil
+}
+
+// Migrations returns the registered migrations ordered by version
+func (m *Migrator) Migrations() []Migration {
+	migrations := make([]Migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations
+}
+
+// Status lists the registered migrations and the applied ones that aren't registered,
+// ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.Migrations() {
+		status := Status{Version: migration.Version, Description: migration.Description, Registered: true}
+		if rec, ok := applied[migration.Version]; ok {
+			status.Applied, status.AppliedAt = true, rec.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, rec := range applied {
+		if _, registered := m.migrations[version]; !registered {
+			statuses = append(statuses, Status{
+				Version:     version,
+				Description: rec.Description,
+				Applied:     true,
+				AppliedAt:   rec.AppliedAt,
+			})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Up applies the pending migrations in version order and returns the migrations
+// applied, or that would be applied in a dry run. It stops at the first failing
+// migration; the migrations applied before it stay applied.
+func (m *Migrator) Up(ctx context.Context, opts ...RunOptions) ([]Migration, error) {
+	var o RunOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if m.Collection == nil {
+		return nil, errors.ErrNilCollection
+	}
+
+	if o.DryRun {
+		pending, err := m.pending(ctx, o.Steps)
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range pending {
+			log.Printf("📋 Would apply migration %d: %s", migration.Version, migration.Description)
+		}
+		return pending, nil
+	}
+
+	ctx, lock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	// Pending migrations are listed under the lock, so none is applied twice
+	pending, err := m.pending(ctx, o.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, migration := range pending {
+		// Stop if another process took the lock over while the last migration ran
+		if err := lock.renew(ctx); err != nil {
+			return applied, err
+		}
+
+		err := m.run(ctx, migration, migration.Up, func(ctx context.Context) error {
+			_, err := m.Collection.InsertOne(ctx, record{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now().UTC(),
+			})
+			return err
+		})
+		if err != nil {
+			err = lostLockCause(ctx, err)
+			log.Printf("⚠️ Failed to apply migration %d: %v", migration.Version, err)
+			return applied, errors.Wrap(err, fmt.Sprintf("failed to apply migration %d", migration.Version))
+		}
+
+		log.Printf("✅ Applied migration %d: %s", migration.Version, migration.Description)
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the applied migrations from the newest one and returns the migrations
+// reverted, or that would be reverted in a dry run. It reverts the last applied
+// migration unless RunOptions.Steps is set, and fails without reverting anything when
+// one of them isn't registered or has no Down step.
+func (m *Migrator) Down(ctx context.Context, opts ...RunOptions) ([]Migration, error) {
+	var o RunOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Steps <= 0 {
+		o.Steps = 1
+	}
+	if m.Collection == nil {
+		return nil, errors.ErrNilCollection
+	}
+
+	if o.DryRun {
+		revertible, err := m.revertible(ctx, o.Steps)
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range revertible {
+			log.Printf("📋 Would revert migration %d: %s", migration.Version, migration.Description)
+		}
+		return revertible, nil
+	}
+
+	ctx, lock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	revertible, err := m.revertible(ctx, o.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := make([]Migration, 0, len(revertible))
+	for _, migration := range revertible {
+		if err := lock.renew(ctx); err != nil {
+			return reverted, err
+		}
+
+		version := migration.Version
+		err := m.run(ctx, migration, migration.Down, func(ctx context.Context) error {
+			_, err := m.Collection.DeleteOne(ctx, bson.M{"_id": version})
+			return err
+		})
+		if err != nil {
+			err = lostLockCause(ctx, err)
+			log.Printf("⚠️ Failed to revert migration %d: %v", version, err)
+			return reverted, errors.Wrap(err, fmt.Sprintf("failed to revert migration %d", version))
+		}
+
+		log.Printf("✅ Reverted migration %d: %s", version, migration.Description)
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// pending returns the registered migrations that weren't applied, at most steps
+// of them unless steps is zero
+func (m *Migrator) pending(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.Migrations() {
+		if steps > 0 && len(pending) == steps {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// revertible returns the last steps applied migrations, newest first
+func (m *Migrator) revertible(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if len(versions) > steps {
+		versions = versions[:steps]
+	}
+
+	revertible := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		migration, registered := m.migrations[version]
+		if !registered {
+			return nil, errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("applied migration %d is not registered", version))
+		}
+		if migration.Down == nil {
+			return nil, errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("migration %d has no down step", version))
+		}
+		revertible = append(revertible, migration)
+	}
+	return revertible, nil
+}
+
+// applied returns the records of the applied migrations by version
+func (m *Migrator) applied(ctx context.Context) (map[int64]record, error) {
+	if m.Collection == nil {
+		return nil, errors.ErrNilCollection
+	}
+
+	// The lock document shares the collection; records have a numeric _id
+	cursor, err := m.Collection.Find(ctx, bson.M{"_id": bson.M{"$type": "number"}})
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrDatabase, err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(errors.ErrDecoding, err.Error())
+	}
+
+	applied := make(map[int64]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// run runs a step of a migration and then updates its record, both in a transaction
+// when the migration is transactional and the server supports transactions
+func (m *Migrator) run(ctx context.Context, migration Migration, step Func, updateRecord func(context.Context) error) error {
+	if migration.Transactional {
+		if m.supportsTransactions(ctx) {
+			return m.Client.ExecuteTransaction(ctx, func(sc mongo.SessionContext) error {
+				if err := step(sc, m.Client); err != nil {
+					return err
+				}
+				return wrapDatabaseError(updateRecord(sc))
+			})
+		}
+		log.Printf("⚠️ Server doesn't support transactions, running migration %d without one", migration.Version)
+	}
+
+	if err := step(ctx, m.Client); err != nil {
+		return err
+	}
+	return wrapDatabaseError(updateRecord(ctx))
+}
+
+// supportsTransactions reports whether the server is a replica set member or a
+// mongos, the deployments supporting transactions
+func (m *Migrator) supportsTransactions(ctx context.Context) bool {
+	if m.Client == nil || m.Client.MongoClient == nil {
+		return false
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := m.Client.MongoClient.Database("admin").
+		RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		log.Printf("⚠️ Failed to check transaction support: %v", err)
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// lostLockCause returns ErrMigrationLocked instead of the error of a step canceled
+// because the lock was taken over
+func lostLockCause(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.IsMigrationLocked(cause) {
+		return cause
+	}
+	return err
+}
+
+// wrapDatabaseError wraps an error of a write to the migrations collection
+func wrapDatabaseError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrap(errors.ErrDatabase, err.Error())
+}
@@ -3,7 +3,9 @@ package model
 
 import (
 	"context"
+	"github.com/isimtekin/merhongo/connection"
 	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
 	"github.com/isimtekin/merhongo/schema"
 	"log"
 	"reflect"
@@ -21,6 +23,8 @@ type Model struct {
 	Schema     *schema.Schema
 	Collection *mongo.Collection
 	DB         *mongo.Database
+	// Client is the connection the model is registered with, used to resolve references
+	Client *connection.Client
 }
 
 // GenericModel extends Model with type-safe operations for a specific document type
@@ -28,8 +32,15 @@ type GenericModel[T any] struct {
 	*Model
 }
 
+// Options configures the creation of a model
+type Options struct {
+	// SkipIndexes leaves the indexes of the collection as they are. By default the
+	// missing indexes the schema declares are created; SyncIndexes creates them later.
+	SkipIndexes bool
+}
+
 // New creates a new model for the given collection
-func New(name string, schema *schema.Schema, db *mongo.Database) *Model {
+func New(name string, schema *schema.Schema, db *mongo.Database, opts ...Options) *Model {
 	collName := schema.Collection
 	if collName == "" {
 		collName = name
@@ -47,40 +58,25 @@ func New(name string, schema *schema.Schema, db *mongo.Database) *Model {
 		DB:         db,
 	}
 
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
 	// Only create indexes if db/collection is initialized
-	if model.Collection != nil {
-		for fieldName, field := range schema.Fields {
-			if field.Index || field.Unique {
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				indexOptions := options.Index()
-				if field.Unique {
-					indexOptions.SetUnique(true)
-				}
-
-				indexModel := mongo.IndexModel{
-					Keys:    bson.D{{Key: fieldName, Value: 1}},
-					Options: indexOptions,
-				}
-				_, err := model.Collection.Indexes().CreateOne(ctx, indexModel)
-				cancel()
-				if err != nil {
-					log.Printf("⚠️ Failed to create index for field '%s': %v", fieldName, err)
-				} else {
-					indexType := "index"
-					if field.Unique {
-						indexType = "unique index"
-					}
-					log.Printf("✅ Created %s for field '%s'", indexType, fieldName)
-				}
-			}
+	if model.Collection != nil && !o.SkipIndexes {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if _, err := model.SyncIndexes(ctx); err != nil {
+			log.Printf("⚠️ Failed to create indexes for collection '%s': %v", collName, err)
 		}
+		cancel()
 	}
 
 	return model
 }
 
 // NewGeneric creates a new generic model with type-safe operations
-func NewGeneric[T any](name string, schema *schema.Schema, db *mongo.Database) *GenericModel[T] {
+func NewGeneric[T any](name string, schema *schema.Schema, db *mongo.Database, opts ...Options) *GenericModel[T] {
 	// Set the model type in the schema for validation purposes
 	var modelType T
 	if schema != nil {
@@ -88,47 +84,8 @@ func NewGeneric[T any](name string, schema *schema.Schema, db *mongo.Database) *
 	}
 
 	return &GenericModel[T]{
-		Model: New(name, schema, db),
-	}
-}
-
-// prepareUpdate prepares update data with timestamp handling
-func (m *Model) prepareUpdate(update interface{}) (map[string]interface{}, error) {
-	var finalUpdate map[string]interface{}
-
-	// Convert update to map format
-	switch u := update.(type) {
-	case map[string]interface{}:
-		finalUpdate = make(map[string]interface{})
-		for k, v := range u {
-			finalUpdate[k] = v
-		}
-	case bson.M:
-		finalUpdate = make(map[string]interface{})
-		for k, v := range u {
-			finalUpdate[k] = v
-		}
-	case bson.D:
-		finalUpdate = make(map[string]interface{})
-		for _, e := range u {
-			finalUpdate[e.Key] = e.Value
-		}
-	default:
-		// Support for other types can be added using reflection
-		// but for now let's support only common types
-		return nil, errors.WithDetails(errors.ErrValidation, "update must be a map or bson type")
-	}
-
-	// Remove createdAt field (should not be modified)
-	delete(finalUpdate, "createdAt")
-	delete(finalUpdate, "CreatedAt")
-
-	// Add/update updatedAt field if timestamps are enabled
-	if m.Schema != nil && m.Schema.Timestamps {
-		finalUpdate["updatedAt"] = time.Now()
+		Model: New(name, schema, db, opts...),
 	}
-
-	return finalUpdate, nil
 }
 
 // addTimestamps adds timestamps to the document
@@ -144,6 +101,20 @@ func (m *Model) addTimestamps(doc interface{}, isNew bool) {
 
 	now := time.Now()
 
+	// Map documents store the timestamps under their field names
+	if val.Kind() == reflect.Map {
+		if isDocumentMap(val) {
+			if isNew {
+				val.SetMapIndex(reflect.ValueOf("createdAt"), reflect.ValueOf(now))
+			}
+			val.SetMapIndex(reflect.ValueOf("updatedAt"), reflect.ValueOf(now))
+		}
+		return
+	}
+	if val.Kind() != reflect.Struct {
+		return
+	}
+
 	// Set CreatedAt for new documents
 	createdField := val.FieldByName("CreatedAt")
 	if createdField.IsValid() && createdField.CanSet() && isNew {
@@ -159,6 +130,10 @@ func (m *Model) addTimestamps(doc interface{}, isNew bool) {
 
 // applyMiddlewares applies middleware functions to a document
 func (m *Model) applyMiddlewares(event string, doc interface{}) error {
+	if m.Schema == nil {
+		return nil
+	}
+
 	middlewares := m.Schema.Middlewares[event]
 	for _, middleware := range middlewares {
 		if err := middleware(doc); err != nil {
@@ -168,36 +143,83 @@ func (m *Model) applyMiddlewares(event string, doc interface{}) error {
 	return nil
 }
 
-// Create inserts a new document into the collection
-func (m *Model) Create(ctx context.Context, doc interface{}) error {
+// applyDefaults sets the schema defaults of a new struct or map document; other documents are left as is
+func (m *Model) applyDefaults(doc interface{}) error {
+	val := reflect.ValueOf(doc)
+	isStruct := val.Kind() == reflect.Ptr && val.Elem().Kind() == reflect.Struct
+	val = reflect.Indirect(val)
+	isMap := val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String
+	if m.Schema == nil || !isStruct && !isMap {
+		return nil
+	}
+	return m.Schema.ApplyDefaults(doc)
+}
+
+// prepareInsert applies the schema defaults and string transforms of a new document, runs
+// its pre-save and pre-validate middlewares, validates it and adds its timestamps and version. It returns the document to insert
+// and the hook context of the save event.
+func (m *Model) prepareInsert(ctx context.Context, doc interface{}, operation string) (interface{}, *schema.HookContext, error) {
+	// Fill zero-valued fields with their schema defaults and transform strings
+	if err := m.applyDefaults(doc); err != nil {
+		return nil, nil, err
+	}
+	if m.Schema != nil {
+		m.Schema.ApplyTransforms(doc)
+	}
+
 	// Apply pre-save middlewares
 	if err := m.applyMiddlewares("save", doc); err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	hc := m.newHookContext(ctx, "save", operation)
+	hc.Document = doc
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	// Apply pre-validate middlewares
+	if err := m.runValidateHooks(ctx, operation, doc); err != nil {
+		return nil, nil, err
 	}
 
 	// Validate document against schema
 	if err := m.Schema.ValidateDocument(doc); err != nil {
-		return errors.Wrap(errors.ErrValidation, err.Error())
+		log.Printf("⚠️ Document validation failed: %v", err)
+		return nil, nil, err
 	}
 
 	// Add timestamps
 	m.addTimestamps(doc, true)
 
+	// Start versioned documents at version 0
+	insertDoc, err := m.withInitialVersion(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return insertDoc, hc, nil
+}
+
+// Create inserts a new document into the collection
+func (m *Model) Create(ctx context.Context, doc interface{}) error {
+	insertDoc, hc, err := m.prepareInsert(ctx, doc, "Create")
+	if err != nil {
+		return err
+	}
+
 	// Insert document and set ID back to struct
-	result, err := m.Collection.InsertOne(ctx, doc)
+	result, err := m.Collection.InsertOne(ctx, insertDoc)
 	if err != nil {
 		log.Printf("⚠️ Failed to insert document: %v", err)
 		return errors.Wrap(errors.ErrDatabase, "failed to create document")
 	}
 
-	// Set ID back to the struct, if field is named ID and is settable
-	val := reflect.ValueOf(doc).Elem()
-	idField := val.FieldByName("ID")
-	if idField.IsValid() && idField.CanSet() {
-		idField.Set(reflect.ValueOf(result.InsertedID))
-	}
+	// Set ID back to the document, if it has a settable ID field or is a map
+	setID(doc, result.InsertedID)
 
-	return nil
+	// Apply post-save middlewares
+	return m.runPostHooks(hc, doc)
 }
 
 // Create inserts a new document with type safety
@@ -213,7 +235,12 @@ func (m *Model) FindById(ctx context.Context, id string, result interface{}) err
 		return errors.WithDetails(errors.ErrInvalidObjectID, err.Error())
 	}
 
-	filter := bson.M{"_id": objectID}
+	hc := m.newHookContext(ctx, "find", "FindById")
+	filter, err := m.prepareFilter(hc, bson.M{"_id": objectID}, query.ScopeExcludeDeleted)
+	if err != nil {
+		return err
+	}
+
 	err = m.Collection.FindOne(ctx, filter).Decode(result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -224,7 +251,7 @@ func (m *Model) FindById(ctx context.Context, id string, result interface{}) err
 		return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
 	}
 
-	return nil
+	return m.runPostHooks(hc, result)
 }
 
 // FindById finds a document by its ID with type safety
@@ -243,6 +270,12 @@ func (m *Model) Find(ctx context.Context, filter interface{}, results interface{
 		return errors.ErrNilCollection
 	}
 
+	hc := m.newHookContext(ctx, "find", "Find")
+	filter, err := m.prepareFilter(hc, filter, query.ScopeExcludeDeleted)
+	if err != nil {
+		return err
+	}
+
 	cursor, err := m.Collection.Find(ctx, filter)
 	if err != nil {
 		log.Printf("⚠️ Failed to retrieve documents: %v", err)
@@ -260,7 +293,7 @@ func (m *Model) Find(ctx context.Context, filter interface{}, results interface{
 		return errors.Wrap(errors.ErrDecoding, err.Error())
 	}
 
-	return nil
+	return m.runPostHooks(hc, results)
 }
 
 // Find finds documents matching the filter with type safety
@@ -275,7 +308,13 @@ func (m *GenericModel[T]) Find(ctx context.Context, filter interface{}) ([]T, er
 
 // FindOne finds a single document matching the filter
 func (m *Model) FindOne(ctx context.Context, filter interface{}, result interface{}) error {
-	err := m.Collection.FindOne(ctx, filter).Decode(result)
+	hc := m.newHookContext(ctx, "find", "FindOne")
+	filter, err := m.prepareFilter(hc, filter, query.ScopeExcludeDeleted)
+	if err != nil {
+		return err
+	}
+
+	err = m.Collection.FindOne(ctx, filter).Decode(result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Printf("⚠️ Document not found with filter: %v", filter)
@@ -285,7 +324,7 @@ func (m *Model) FindOne(ctx context.Context, filter interface{}, result interfac
 		return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
 	}
 
-	return nil
+	return m.runPostHooks(hc, result)
 }
 
 // FindOne finds a single document matching the filter with type safety
@@ -306,64 +345,48 @@ func (m *Model) UpdateById(ctx context.Context, id string, update interface{}) e
 		return errors.WithDetails(errors.ErrInvalidObjectID, err.Error())
 	}
 
-	// 1. First find the existing document
-	filter := bson.M{"_id": objectID}
-	result := m.Collection.FindOne(ctx, filter)
-	if result.Err() != nil {
-		if result.Err() == mongo.ErrNoDocuments {
-			log.Printf("⚠️ Document not found with ID: %s", id)
-			return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
-		}
-		log.Printf("⚠️ Failed to retrieve document with ID %s for update: %v", id, result.Err())
-		return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
-	}
-
-	// 2. Load the existing document as a map
-	var existingDoc bson.M
-	if err := result.Decode(&existingDoc); err != nil {
-		log.Printf("⚠️ Failed to decode document with ID %s: %v", id, err)
-		return errors.Wrap(errors.ErrDecoding, "failed to decode document")
-	}
-
-	// 3. Prepare update data (handle timestamps)
+	// 1. Prepare update data (handle timestamps)
 	finalUpdate, err := m.prepareUpdate(update)
 	if err != nil {
 		return err
 	}
 
-	// 4. Apply update data to the existing document
-	for key, value := range finalUpdate {
-		existingDoc[key] = value
+	// 2. Run pre-update hooks, which may scope the filter or change the update
+	hc := m.newHookContext(ctx, "update", "UpdateById")
+	hc.Filter = m.applySoftDelete(bson.M{"_id": objectID}, query.ScopeExcludeDeleted)
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return err
 	}
 
-	// 5. Validate the full updated document
-	if m.Schema != nil && m.Schema.ModelType != nil {
-		// Create a new instance of the model type
-		docType := reflect.TypeOf(m.Schema.ModelType).Elem()
-		newInstance := reflect.New(docType).Interface()
-
-		// Convert existingDoc to struct
-		bytes, _ := bson.Marshal(existingDoc)
-		if err := bson.Unmarshal(bytes, newInstance); err != nil {
-			log.Printf("⚠️ Failed to convert document to struct for validation: %v", err)
-			return errors.Wrap(errors.ErrDecoding, "failed to convert to struct for validation")
+	// 3. Apply the update to the existing document and validate the result
+	matched, updatedDoc, err := m.simulateUpdate(ctx, hc, nil, false)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
 		}
+		return err
+	}
 
-		// Validate the document
-		if err := m.Schema.ValidateDocument(newInstance); err != nil {
-			log.Printf("⚠️ Document validation failed: %v", err)
-			return err
-		}
+	// 4. Apply the update, only to the version that was validated on versioned schemas
+	filter := hc.Filter
+	if m.versionEnabled() {
+		filter = cloneFilter(hc.Filter)
+		filter[m.Schema.VersionKey] = versionCondition(matched[m.Schema.VersionKey])
 	}
 
-	// 6. Apply the update
-	_, err = m.Collection.UpdateOne(ctx, filter, bson.M{"$set": finalUpdate})
+	result, err := m.Collection.UpdateOne(ctx, filter, hc.Update)
 	if err != nil {
 		log.Printf("⚠️ Failed to update document with ID %s: %v", id, err)
 		return errors.Wrap(errors.ErrDatabase, "failed to update document")
 	}
 
-	return nil
+	if result.MatchedCount == 0 {
+		return m.matchFailure(ctx, hc.Filter, id)
+	}
+
+	// 5. Apply post-update middlewares with the updated document
+	return m.runPostHooks(hc, updatedDoc)
 }
 
 // UpdateById updates a document by its ID with type safety
@@ -371,22 +394,86 @@ func (m *GenericModel[T]) UpdateById(ctx context.Context, id string, update inte
 	return m.Model.UpdateById(ctx, id, update)
 }
 
-// DeleteById deletes a document by its ID
+// DeleteById deletes a document by its ID. On soft-delete schemas the document
+// is marked as deleted instead of being removed.
 func (m *Model) DeleteById(ctx context.Context, id string) error {
+	return m.deleteById(ctx, id, "DeleteById", !m.softDeleteEnabled())
+}
+
+// deleteById deletes a document by its ID, either removing it or marking it as soft-deleted
+func (m *Model) deleteById(ctx context.Context, id string, operation string, hard bool) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		log.Printf("⚠️ Invalid ObjectID format: %s - %v", id, err)
 		return errors.WithDetails(errors.ErrInvalidObjectID, err.Error())
 	}
 
-	filter := bson.M{"_id": objectID}
-	result, err := m.Collection.DeleteOne(ctx, filter)
-	if err != nil {
-		log.Printf("⚠️ Failed to delete document with ID %s: %v", id, err)
-		return errors.Wrap(errors.ErrDatabase, "failed to delete document")
+	hc := m.newHookContext(ctx, "delete", operation)
+	hc.Filter = bson.M{"_id": objectID}
+	if !hard {
+		hc.Filter = m.applySoftDelete(hc.Filter, query.ScopeExcludeDeleted)
+	}
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
+	// Pre-delete middlewares receive the document about to be deleted
+	if m.Schema != nil && len(m.Schema.Middlewares["delete"]) > 0 {
+		var existingDoc bson.M
+		err = m.Collection.FindOne(ctx, hc.Filter).Decode(&existingDoc)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				log.Printf("⚠️ Document not found with ID: %s", id)
+				return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
+			}
+			log.Printf("⚠️ Failed to retrieve document with ID %s for delete: %v", id, err)
+			return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
+		}
+
+		if err := m.applyMiddlewares("delete", m.documentPayload(existingDoc)); err != nil {
+			return err
+		}
+	}
+
+	// Post-delete middlewares receive the deleted document, so fetch it atomically
+	if m.hasPostHooks("delete") {
+		var deletedDoc bson.M
+		if hard {
+			err = m.Collection.FindOneAndDelete(ctx, hc.Filter).Decode(&deletedDoc)
+		} else {
+			opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+			err = m.Collection.FindOneAndUpdate(ctx, hc.Filter, m.softDeleteUpdate(), opts).Decode(&deletedDoc)
+		}
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				log.Printf("⚠️ Document not found with ID: %s", id)
+				return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
+			}
+			log.Printf("⚠️ Failed to delete document with ID %s: %v", id, err)
+			return errors.Wrap(errors.ErrDatabase, "failed to delete document")
+		}
+
+		return m.runPostHooks(hc, m.documentPayload(deletedDoc))
 	}
 
-	if result.DeletedCount == 0 {
+	var deletedCount int64
+	if hard {
+		result, err := m.Collection.DeleteOne(ctx, hc.Filter)
+		if err != nil {
+			log.Printf("⚠️ Failed to delete document with ID %s: %v", id, err)
+			return errors.Wrap(errors.ErrDatabase, "failed to delete document")
+		}
+		deletedCount = result.DeletedCount
+	} else {
+		result, err := m.Collection.UpdateOne(ctx, hc.Filter, m.softDeleteUpdate())
+		if err != nil {
+			log.Printf("⚠️ Failed to soft-delete document with ID %s: %v", id, err)
+			return errors.Wrap(errors.ErrDatabase, "failed to delete document")
+		}
+		deletedCount = result.MatchedCount
+	}
+
+	if deletedCount == 0 {
 		log.Printf("⚠️ Document not found with ID: %s", id)
 		return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
 	}
@@ -405,6 +492,12 @@ func (m *Model) Count(ctx context.Context, filter interface{}) (int64, error) {
 		return 0, errors.ErrNilCollection
 	}
 
+	hc := m.newHookContext(ctx, "count", "Count")
+	filter, err := m.prepareFilter(hc, filter, query.ScopeExcludeDeleted)
+	if err != nil {
+		return 0, err
+	}
+
 	count, err := m.Collection.CountDocuments(ctx, filter)
 	if err != nil {
 		log.Printf("⚠️ Failed to count documents: %v", err)
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"log"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Aggregate runs an aggregation pipeline and decodes its output into results. On
+// soft-delete schemas soft-deleted documents are left out unless the pipeline includes
+// them, and pre-find middlewares may scope the documents entering the pipeline, see
+// scopePipeline.
+func (m *Model) Aggregate(ctx context.Context, pipeline *query.Pipeline, results interface{}) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+
+	if pipeline == nil {
+		return errors.WithDetails(errors.ErrValidation, "pipeline cannot be nil")
+	}
+
+	stages, err := pipeline.Build()
+	if err != nil {
+		log.Printf("⚠️ Failed to build pipeline: %v", err)
+		return errors.Wrap(err, "failed to build pipeline")
+	}
+
+	// Run pre-find hooks, which may scope the documents entering the pipeline
+	hc := m.newHookContext(ctx, "find", "Aggregate")
+	hc.Filter = m.applySoftDelete(bson.M{}, pipeline.DeletedScope())
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+	stages = scopePipeline(stages, hc.Filter)
+
+	// Execute the pipeline
+	cursor, err := m.Collection.Aggregate(ctx, stages)
+	if err != nil {
+		log.Printf("⚠️ Failed to run aggregation: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "failed to run aggregation")
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Printf("⚠️ Failed to close cursor: %v", err)
+		}
+	}()
+
+	// Decode the results
+	if err := cursor.All(ctx, results); err != nil {
+		log.Printf("⚠️ Failed to decode aggregation results: %v", err)
+		return errors.Wrap(errors.ErrDecoding, err.Error())
+	}
+
+	return nil
+}
+
+// scopePipeline restricts the documents entering a pipeline to those matching a filter,
+// with a leading $match stage. Stages that must come first are kept first: the filter
+// is merged into the query of $geoNear and follows $search and $vectorSearch, and
+// pipelines starting with a stage that doesn't read the collection's documents, like
+// $collStats or $indexStats, are left unchanged.
+func scopePipeline(stages mongo.Pipeline, filter bson.M) mongo.Pipeline {
+	if len(filter) == 0 {
+		return stages
+	}
+
+	match := bson.D{{Key: "$match", Value: filter}}
+	if len(stages) == 0 || len(stages[0]) == 0 {
+		return append(mongo.Pipeline{match}, stages...)
+	}
+
+	switch stages[0][0].Key {
+	case "$geoNear":
+		spec, err := toDocumentMap(stages[0][0].Value)
+		if err != nil {
+			return stages
+		}
+		if existing, ok := spec["query"]; ok && existing != nil {
+			spec["query"] = bson.M{"$and": bson.A{existing, filter}}
+		} else {
+			spec["query"] = filter
+		}
+		return append(mongo.Pipeline{{{Key: "$geoNear", Value: spec}}}, stages[1:]...)
+	case "$search", "$vectorSearch":
+		return append(mongo.Pipeline{stages[0], match}, stages[1:]...)
+	case "$collStats", "$indexStats", "$searchMeta", "$changeStream", "$currentOp",
+		"$listSessions", "$listLocalSessions", "$listSearchIndexes", "$documents":
+		return stages
+	}
+	return append(mongo.Pipeline{match}, stages...)
+}
+
+// Aggregate runs an aggregation pipeline on a model and decodes its output into
+// values of the result type, which usually differs from the model's document type
+func Aggregate[R any](ctx context.Context, m *Model, pipeline *query.Pipeline) ([]R, error) {
+	var results []R
+	if err := m.Aggregate(ctx, pipeline, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
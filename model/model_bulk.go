@@ -0,0 +1,205 @@
+package model
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateManyOptions contains optional settings for CreateMany
+type CreateManyOptions struct {
+	// Unordered keeps going after a document fails. By default documents are
+	// processed in order and the first failure stops the batch.
+	Unordered bool
+}
+
+// CreateMany inserts several documents with a single round trip. Every document goes through
+// the save middlewares, schema validation and timestamps like in Create, and the generated
+// IDs are set back on the documents. When documents fail validation or insertion, the
+// returned error is an *errors.BatchError listing their indexes; the other documents
+// are still inserted, up to the first failure in ordered mode.
+func (m *Model) CreateMany(ctx context.Context, docs []interface{}, opts ...CreateManyOptions) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+
+	var o CreateManyOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	batchErr := &errors.BatchError{}
+	var insertDocs []interface{}
+	var insertIndexes []int
+	var hookContexts []*schema.HookContext
+	var generatedIDs []bool
+
+	// 1. Run the pre-save pipeline of each document
+	for i, doc := range docs {
+		insertDoc, hc, err := m.prepareInsert(ctx, doc, "CreateMany")
+		if err != nil {
+			batchErr.Add(i, err)
+			if !o.Unordered {
+				break
+			}
+			continue
+		}
+
+		// Generate ObjectIDs up front, so they can be set back on the documents
+		// without relying on the order of the driver's result
+		if id, ok := assignObjectID(doc); ok {
+			if docMap, isMap := insertDoc.(bson.M); isMap {
+				docMap["_id"] = id
+			}
+			generatedIDs = append(generatedIDs, true)
+		} else {
+			generatedIDs = append(generatedIDs, false)
+		}
+
+		insertDocs = append(insertDocs, insertDoc)
+		insertIndexes = append(insertIndexes, i)
+		hookContexts = append(hookContexts, hc)
+	}
+
+	if len(insertDocs) == 0 {
+		return batchErr.ErrOrNil()
+	}
+
+	// 2. Insert the valid documents
+	result, err := m.Collection.InsertMany(ctx, insertDocs, options.InsertMany().SetOrdered(!o.Unordered))
+
+	inserted := make([]bool, len(insertDocs))
+	for j := range inserted {
+		inserted[j] = true
+	}
+
+	if err != nil {
+		var writeErr mongo.BulkWriteException
+		if !stderrors.As(err, &writeErr) || len(writeErr.WriteErrors) == 0 {
+			log.Printf("⚠️ Failed to insert documents: %v", err)
+			return errors.Wrap(errors.ErrDatabase, "failed to create documents")
+		}
+
+		for _, we := range writeErr.WriteErrors {
+			log.Printf("⚠️ Failed to insert document %d: %v", insertIndexes[we.Index], we.Message)
+			batchErr.Add(insertIndexes[we.Index], errors.WithDetails(errors.ErrDatabase, we.Message))
+			inserted[we.Index] = false
+		}
+
+		// An ordered insert stops at its first failing document
+		if !o.Unordered {
+			for j := writeErr.WriteErrors[0].Index; j < len(inserted); j++ {
+				inserted[j] = false
+			}
+		}
+	}
+
+	// 3. Set IDs back on the documents and apply post-save middlewares
+	for j, index := range insertIndexes {
+		doc := docs[index]
+		if !inserted[j] {
+			if generatedIDs[j] {
+				clearObjectID(doc)
+			}
+			continue
+		}
+
+		if result != nil && len(result.InsertedIDs) == len(insertDocs) {
+			setID(doc, result.InsertedIDs[j])
+		}
+
+		if err := m.runPostHooks(hookContexts[j], doc); err != nil {
+			batchErr.Add(index, err)
+		}
+	}
+
+	sort.Slice(batchErr.Errors, func(i, j int) bool {
+		return batchErr.Errors[i].Index < batchErr.Errors[j].Index
+	})
+	return batchErr.ErrOrNil()
+}
+
+// CreateMany inserts several documents with type safety
+func (m *GenericModel[T]) CreateMany(ctx context.Context, docs []*T, opts ...CreateManyOptions) error {
+	items := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		items[i] = doc
+	}
+	return m.Model.CreateMany(ctx, items, opts...)
+}
+
+// idField returns the settable ID field of a struct document, if it has one
+func idField(doc interface{}) (reflect.Value, bool) {
+	val := reflect.ValueOf(doc)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	field := val.Elem().FieldByName("ID")
+	if !field.IsValid() || !field.CanSet() {
+		return reflect.Value{}, false
+	}
+	return field, true
+}
+
+// assignObjectID sets a new ObjectID on a document with an empty ObjectID field
+// and reports whether it did
+func assignObjectID(doc interface{}) (primitive.ObjectID, bool) {
+	field, ok := idField(doc)
+	if !ok || field.Type() != reflect.TypeOf(primitive.ObjectID{}) || !field.IsZero() {
+		return primitive.ObjectID{}, false
+	}
+	id := primitive.NewObjectID()
+	field.Set(reflect.ValueOf(id))
+	return id, true
+}
+
+// clearObjectID resets the ObjectID of a document that was not inserted
+func clearObjectID(doc interface{}) {
+	field, ok := idField(doc)
+	if !ok || field.Type() != reflect.TypeOf(primitive.ObjectID{}) {
+		return
+	}
+	field.Set(reflect.Zero(field.Type()))
+}
+
+// isDocumentMap reports whether a value is a non-nil map of field names to values, like bson.M
+func isDocumentMap(val reflect.Value) bool {
+	return val.Kind() == reflect.Map && !val.IsNil() &&
+		val.Type().Key() == reflect.TypeOf("") && val.Type().Elem().Kind() == reflect.Interface
+}
+
+// setID sets the inserted ID on a struct document when the types match, or under
+// _id in a map document
+func setID(doc interface{}, id interface{}) {
+	if id == nil {
+		return
+	}
+
+	val := reflect.ValueOf(doc)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if isDocumentMap(val) {
+		val.SetMapIndex(reflect.ValueOf("_id"), reflect.ValueOf(id))
+		return
+	}
+
+	field, ok := idField(doc)
+	if !ok {
+		return
+	}
+	idVal := reflect.ValueOf(id)
+	if idVal.Type().AssignableTo(field.Type()) {
+		field.Set(idVal)
+	}
+}
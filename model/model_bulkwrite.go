@@ -0,0 +1,538 @@
+package model
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkOperation is a single write of a BulkWrite: an InsertOperation, UpdateOneOperation,
+// UpdateManyOperation, ReplaceOneOperation, DeleteOneOperation or DeleteManyOperation
+type BulkOperation interface {
+	bulkOperation()
+}
+
+// InsertOperation inserts a document, like Create
+type InsertOperation struct {
+	Document interface{}
+}
+
+// UpdateOneOperation updates the first document matching the filter, like UpdateById.
+// The update accepts the same forms as UpdateById.
+type UpdateOneOperation struct {
+	Filter interface{}
+	Update interface{}
+	// Upsert inserts a document when none matches the filter
+	Upsert bool
+}
+
+// UpdateManyOperation updates every document matching the filter, like UpdateWithQuery
+type UpdateManyOperation struct {
+	Filter interface{}
+	Update interface{}
+}
+
+// ReplaceOneOperation replaces the first document matching the filter, like FindOneAndReplace
+type ReplaceOneOperation struct {
+	Filter      interface{}
+	Replacement interface{}
+	// Upsert inserts the replacement when no document matches the filter
+	Upsert bool
+}
+
+// DeleteOneOperation deletes the first document matching the filter, like DeleteById
+type DeleteOneOperation struct {
+	Filter interface{}
+}
+
+// DeleteManyOperation deletes every document matching the filter, like DeleteWithQuery
+type DeleteManyOperation struct {
+	Filter interface{}
+}
+
+func (InsertOperation) bulkOperation()     {}
+func (UpdateOneOperation) bulkOperation()  {}
+func (UpdateManyOperation) bulkOperation() {}
+func (ReplaceOneOperation) bulkOperation() {}
+func (DeleteOneOperation) bulkOperation()  {}
+func (DeleteManyOperation) bulkOperation() {}
+
+// BulkWriteOptions contains optional settings for BulkWrite
+type BulkWriteOptions struct {
+	// Unordered keeps going after an operation fails. By default operations are
+	// applied in order and the first failure stops the batch.
+	Unordered bool
+}
+
+// BulkWriteResult holds the outcome of a BulkWrite
+type BulkWriteResult struct {
+	// InsertedCount is the number of documents inserted by insert operations
+	InsertedCount int64
+	// MatchedCount is the number of documents matched by update and replace operations
+	MatchedCount int64
+	// ModifiedCount is the number of documents modified by update and replace operations
+	ModifiedCount int64
+	// DeletedCount is the number of documents deleted, or marked as deleted on soft-delete schemas
+	DeletedCount int64
+	// UpsertedCount is the number of documents inserted by upserts
+	UpsertedCount int64
+	// UpsertedIDs maps the index of an operation to the ID of the document it upserted
+	UpsertedIDs map[int]interface{}
+}
+
+// bulkWrite tracks a prepared operation of a BulkWrite
+type bulkWrite struct {
+	// index is the position of the operation in the batch
+	index int
+	hc    *schema.HookContext
+	// payload is passed to the post middlewares; multi-document operations receive the result
+	payload interface{}
+	many    bool
+	// insertedDoc is the document of an insert, and generatedID whether its ID was generated
+	insertedDoc interface{}
+	generatedID bool
+	// target is the ID of the single document the operation was validated against
+	target interface{}
+	// softDeleteIDs are the IDs of the documents a soft delete marks with softDeleteMark
+	softDeleteIDs  bson.A
+	softDeleteMark time.Time
+}
+
+// BulkWrite applies a list of inserts, updates, replaces and deletes with a single bulk
+// write. Each operation goes through the same middlewares, validation and timestamps as
+// the matching single-document method, against the documents as stored before the batch,
+// so update, replace and delete operations each read the documents they match first.
+// Update, replace and delete operations that match no document are skipped. A document
+// can only be updated, replaced or deleted by one operation of a batch, as the next one
+// would be validated against the document without the changes of the first; the later
+// operations fail with ErrValidation. When operations fail, the returned error is an
+// *errors.BatchError listing their indexes; the other operations are still applied, up
+// to the first failure in ordered mode.
+func (m *Model) BulkWrite(ctx context.Context, ops []BulkOperation, opts ...BulkWriteOptions) (*BulkWriteResult, error) {
+	if m.Collection == nil {
+		return nil, errors.ErrNilCollection
+	}
+
+	var o BulkWriteOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	result := &BulkWriteResult{UpsertedIDs: map[int]interface{}{}}
+	batchErr := &errors.BatchError{}
+	var writeModels []mongo.WriteModel
+	var writes []*bulkWrite
+	targets := make(map[string]int)
+
+	// 1. Run the pre-write pipeline of each operation
+	for i, op := range ops {
+		writeModel, write, err := m.prepareBulkOperation(ctx, op)
+		if err == nil && writeModel != nil && write.target != nil {
+			// A second write of the same document would apply changes nobody validated
+			key := fmt.Sprintf("%T:%v", write.target, write.target)
+			if first, exists := targets[key]; exists {
+				err = errors.WrapWithID(errors.ErrValidation,
+					fmt.Sprintf("document is already written by operation %d of the batch", first), formatID(write.target))
+			} else {
+				targets[key] = i
+			}
+		}
+		if err != nil {
+			batchErr.Add(i, err)
+			if !o.Unordered {
+				break
+			}
+			continue
+		}
+		if writeModel == nil {
+			continue
+		}
+
+		write.index = i
+		writeModels = append(writeModels, writeModel)
+		writes = append(writes, write)
+	}
+
+	if len(writeModels) == 0 {
+		return result, batchErr.ErrOrNil()
+	}
+
+	// 2. Apply the writes
+	bulkResult, err := m.Collection.BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(!o.Unordered))
+
+	written := make([]bool, len(writes))
+	for j := range written {
+		written[j] = true
+	}
+
+	if err != nil {
+		var writeErr mongo.BulkWriteException
+		if !stderrors.As(err, &writeErr) || len(writeErr.WriteErrors) == 0 {
+			log.Printf("⚠️ Failed to apply bulk write: %v", err)
+			return nil, errors.Wrap(errors.ErrDatabase, "failed to apply bulk write")
+		}
+
+		for _, we := range writeErr.WriteErrors {
+			log.Printf("⚠️ Failed to apply bulk operation %d: %v", writes[we.Index].index, we.Message)
+			batchErr.Add(writes[we.Index].index, errors.WithDetails(errors.ErrDatabase, we.Message))
+			written[we.Index] = false
+		}
+
+		// An ordered bulk write stops at its first failing operation
+		if !o.Unordered {
+			for j := writeErr.WriteErrors[0].Index; j < len(written); j++ {
+				written[j] = false
+			}
+		}
+	}
+
+	if bulkResult != nil {
+		result.InsertedCount = bulkResult.InsertedCount
+		result.MatchedCount = bulkResult.MatchedCount
+		result.ModifiedCount = bulkResult.ModifiedCount
+		result.DeletedCount = bulkResult.DeletedCount
+		result.UpsertedCount = bulkResult.UpsertedCount
+		for j, id := range bulkResult.UpsertedIDs {
+			result.UpsertedIDs[writes[j].index] = id
+		}
+	}
+
+	// Soft deletes are written as updates, count them as deletes
+	if softDeleted := m.countSoftDeleted(ctx, writes, written); softDeleted > 0 {
+		result.DeletedCount += softDeleted
+		result.MatchedCount = max(result.MatchedCount-softDeleted, 0)
+		result.ModifiedCount = max(result.ModifiedCount-softDeleted, 0)
+	}
+
+	// 3. Apply post middlewares of the applied operations
+	for j, write := range writes {
+		if !written[j] {
+			if write.generatedID {
+				clearObjectID(write.insertedDoc)
+			}
+			continue
+		}
+
+		payload := write.payload
+		if write.many {
+			payload = result
+		}
+		if err := m.runPostHooks(write.hc, payload); err != nil {
+			batchErr.Add(write.index, err)
+		}
+	}
+
+	sort.Slice(batchErr.Errors, func(i, j int) bool {
+		return batchErr.Errors[i].Index < batchErr.Errors[j].Index
+	})
+	return result, batchErr.ErrOrNil()
+}
+
+// BulkWrite applies a list of write operations with a single bulk write
+func (m *GenericModel[T]) BulkWrite(ctx context.Context, ops []BulkOperation, opts ...BulkWriteOptions) (*BulkWriteResult, error) {
+	return m.Model.BulkWrite(ctx, ops, opts...)
+}
+
+// prepareBulkOperation runs the pre-write pipeline of an operation and returns its write
+// model, or a nil write model when the operation matches no document
+func (m *Model) prepareBulkOperation(ctx context.Context, op BulkOperation) (mongo.WriteModel, *bulkWrite, error) {
+	switch o := op.(type) {
+	case InsertOperation:
+		return m.prepareBulkInsert(ctx, o)
+	case *InsertOperation:
+		return m.prepareBulkInsert(ctx, *o)
+	case UpdateOneOperation:
+		return m.prepareBulkUpdateOne(ctx, o)
+	case *UpdateOneOperation:
+		return m.prepareBulkUpdateOne(ctx, *o)
+	case UpdateManyOperation:
+		return m.prepareBulkUpdateMany(ctx, o)
+	case *UpdateManyOperation:
+		return m.prepareBulkUpdateMany(ctx, *o)
+	case ReplaceOneOperation:
+		return m.prepareBulkReplaceOne(ctx, o)
+	case *ReplaceOneOperation:
+		return m.prepareBulkReplaceOne(ctx, *o)
+	case DeleteOneOperation:
+		return m.prepareBulkDeleteOne(ctx, o)
+	case *DeleteOneOperation:
+		return m.prepareBulkDeleteOne(ctx, *o)
+	case DeleteManyOperation:
+		return m.prepareBulkDeleteMany(ctx, o)
+	case *DeleteManyOperation:
+		return m.prepareBulkDeleteMany(ctx, *o)
+	default:
+		return nil, nil, errors.WithDetails(errors.ErrValidation, "unsupported bulk operation")
+	}
+}
+
+// prepareBulkInsert runs the pre-save pipeline of an insert
+func (m *Model) prepareBulkInsert(ctx context.Context, op InsertOperation) (mongo.WriteModel, *bulkWrite, error) {
+	insertDoc, hc, err := m.prepareInsert(ctx, op.Document, "BulkWrite")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	write := &bulkWrite{hc: hc, payload: op.Document, insertedDoc: op.Document}
+	if id, ok := assignObjectID(op.Document); ok {
+		if docMap, isMap := insertDoc.(bson.M); isMap {
+			docMap["_id"] = id
+		}
+		write.generatedID = true
+	}
+
+	return mongo.NewInsertOneModel().SetDocument(insertDoc), write, nil
+}
+
+// prepareBulkUpdateOne validates the update of the first document matching the filter
+func (m *Model) prepareBulkUpdateOne(ctx context.Context, op UpdateOneOperation) (mongo.WriteModel, *bulkWrite, error) {
+	filterMap, err := toFilterMap(op.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finalUpdate, err := m.prepareUpdate(op.Update)
+	if err != nil {
+		return nil, nil, err
+	}
+	if op.Upsert {
+		m.addInsertOnlyFields(finalUpdate, filterMap)
+	}
+
+	hc := m.newHookContext(ctx, "update", "BulkWrite")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	matched, updatedDoc, err := m.simulateUpdate(ctx, hc, nil, op.Upsert)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	// Only update the document that was validated, at the validated version
+	writeFilter, writeUpdate, upsert := m.upsertWrite(hc.Filter, hc.Update, matched, op.Upsert)
+	writeModel := mongo.NewUpdateOneModel().
+		SetFilter(writeFilter).
+		SetUpdate(writeUpdate).
+		SetUpsert(upsert)
+	return writeModel, &bulkWrite{hc: hc, payload: updatedDoc, target: matched["_id"]}, nil
+}
+
+// prepareBulkUpdateMany validates the update of every document matching the filter
+func (m *Model) prepareBulkUpdateMany(ctx context.Context, op UpdateManyOperation) (mongo.WriteModel, *bulkWrite, error) {
+	filterMap, err := toFilterMap(op.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finalUpdate, err := m.prepareUpdate(op.Update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hc := m.newHookContext(ctx, "updateMany", "BulkWrite")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.applyMiddlewares("updateMany", hc.Filter); err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.validateUpdates(ctx, hc); err != nil {
+		return nil, nil, err
+	}
+
+	// Multi-document updates share the "update" post event with UpdateOne
+	hc.Event = "update"
+	writeModel := mongo.NewUpdateManyModel().
+		SetFilter(hc.Filter).
+		SetUpdate(hc.Update)
+	return writeModel, &bulkWrite{hc: hc, many: true}, nil
+}
+
+// prepareBulkReplaceOne validates the replacement of the first document matching the filter
+func (m *Model) prepareBulkReplaceOne(ctx context.Context, op ReplaceOneOperation) (mongo.WriteModel, *bulkWrite, error) {
+	filterMap, err := toFilterMap(op.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replacementDoc, err := toFilterMap(op.Replacement)
+	if err != nil {
+		return nil, nil, errors.WithDetails(errors.ErrValidation, "replacement must be a document")
+	}
+
+	hc := m.newHookContext(ctx, "update", "BulkWrite")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Document = replacementDoc
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	existingDoc, updatedDoc, err := m.prepareReplacement(ctx, hc, replacementDoc, nil, op.Upsert)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	// Only replace the document that was validated, at the validated version
+	writeModel := mongo.NewReplaceOneModel().
+		SetFilter(m.pinFilter(hc.Filter, existingDoc)).
+		SetReplacement(replacementDoc).
+		SetUpsert(op.Upsert && existingDoc == nil)
+	return writeModel, &bulkWrite{hc: hc, payload: updatedDoc, target: existingDoc["_id"]}, nil
+}
+
+// prepareBulkDeleteOne runs the pre-delete pipeline of the first document matching the filter
+func (m *Model) prepareBulkDeleteOne(ctx context.Context, op DeleteOneOperation) (mongo.WriteModel, *bulkWrite, error) {
+	filterMap, err := toFilterMap(op.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hc := m.newHookContext(ctx, "delete", "BulkWrite")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	write := &bulkWrite{hc: hc}
+	filter := hc.Filter
+
+	// Middlewares receive the document about to be deleted, and soft deletes count it
+	softDelete := m.softDeleteEnabled()
+	if softDelete || m.hasPostHooks("delete") || (m.Schema != nil && len(m.Schema.Middlewares["delete"]) > 0) {
+		var existingDoc bson.M
+		err := m.Collection.FindOne(ctx, hc.Filter).Decode(&existingDoc)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil, nil
+			}
+			log.Printf("⚠️ Failed to retrieve document for delete: %v", err)
+			return nil, nil, errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
+		}
+
+		write.payload = m.documentPayload(existingDoc)
+		if err := m.applyMiddlewares("delete", write.payload); err != nil {
+			return nil, nil, err
+		}
+
+		filter = cloneFilter(hc.Filter)
+		filter["_id"] = existingDoc["_id"]
+		write.target = existingDoc["_id"]
+	}
+
+	if softDelete {
+		update := m.bulkSoftDeleteUpdate(write, bson.A{write.target})
+		return mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update), write, nil
+	}
+	return mongo.NewDeleteOneModel().SetFilter(filter), write, nil
+}
+
+// prepareBulkDeleteMany runs the pre-delete pipeline of every document matching the filter
+func (m *Model) prepareBulkDeleteMany(ctx context.Context, op DeleteManyOperation) (mongo.WriteModel, *bulkWrite, error) {
+	filterMap, err := toFilterMap(op.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hc := m.newHookContext(ctx, "deleteMany", "BulkWrite")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.applyMiddlewares("deleteMany", hc.Filter); err != nil {
+		return nil, nil, err
+	}
+
+	// Multi-document deletes share the "delete" post event with DeleteOne
+	hc.Event = "delete"
+	write := &bulkWrite{hc: hc, many: true}
+
+	if !m.softDeleteEnabled() {
+		return mongo.NewDeleteManyModel().SetFilter(hc.Filter), write, nil
+	}
+
+	// Soft deletes are updates, so find the documents they mark to count them
+	cursor, err := m.Collection.Find(ctx, hc.Filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		log.Printf("⚠️ Failed to retrieve documents for delete: %v", err)
+		return nil, nil, errors.Wrap(errors.ErrDatabase, "failed to retrieve documents")
+	}
+
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		log.Printf("⚠️ Failed to decode documents for delete: %v", err)
+		return nil, nil, errors.Wrap(errors.ErrDecoding, "failed to decode documents")
+	}
+	if len(docs) == 0 {
+		return nil, nil, nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc["_id"]
+	}
+
+	filter := cloneFilter(hc.Filter)
+	filter["_id"] = bson.M{"$in": ids}
+	update := m.bulkSoftDeleteUpdate(write, ids)
+	return mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update), write, nil
+}
+
+// bulkSoftDeleteUpdate returns the update of a soft delete marking the documents with the
+// given IDs. The mark is truncated to the millisecond precision of BSON dates, so the
+// documents the write actually marked can be counted afterwards.
+func (m *Model) bulkSoftDeleteUpdate(write *bulkWrite, ids bson.A) bson.M {
+	write.softDeleteIDs = ids
+	write.softDeleteMark = time.Now().Truncate(time.Millisecond)
+	return bson.M{"$set": bson.M{m.softDeleteField(): write.softDeleteMark}}
+}
+
+// countSoftDeleted counts the documents the written soft deletes marked as deleted. Documents
+// changed or deleted between the preparation and the write of an operation aren't marked,
+// so they are counted from the stored marks rather than from the prepared IDs.
+func (m *Model) countSoftDeleted(ctx context.Context, writes []*bulkWrite, written []bool) int64 {
+	var marked bson.A
+	var prepared int64
+	for j, write := range writes {
+		if written[j] && len(write.softDeleteIDs) > 0 {
+			marked = append(marked, bson.M{
+				"_id":               bson.M{"$in": write.softDeleteIDs},
+				m.softDeleteField(): write.softDeleteMark,
+			})
+			prepared += int64(len(write.softDeleteIDs))
+		}
+	}
+	if len(marked) == 0 {
+		return 0
+	}
+
+	count, err := m.Collection.CountDocuments(ctx, bson.M{"$or": marked})
+	if err != nil {
+		log.Printf("⚠️ Failed to count soft-deleted documents, using the prepared count: %v", err)
+		return prepared
+	}
+	return count
+}
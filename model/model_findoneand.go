@@ -0,0 +1,361 @@
+package model
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindOneAndOptions contains optional settings for the FindOneAnd* methods
+type FindOneAndOptions struct {
+	// ReturnBefore returns the document as it was before the change instead of after it.
+	// It is ignored by FindOneAndDelete, which always returns the deleted document.
+	ReturnBefore bool
+	// Upsert inserts a document when none matches the filter (update and replace only)
+	Upsert bool
+	// Sort selects which document is changed when several match the filter
+	Sort interface{}
+}
+
+// findOneAndOptions returns the first of the given options, or the defaults
+func findOneAndOptions(opts []FindOneAndOptions) FindOneAndOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return FindOneAndOptions{}
+}
+
+// returnDocument converts the ReturnBefore option to the driver's setting
+func (o FindOneAndOptions) returnDocument() options.ReturnDocument {
+	if o.ReturnBefore {
+		return options.Before
+	}
+	return options.After
+}
+
+// FindOneAndUpdate atomically updates a single document matching the filter and decodes
+// it into result, after the update by default. The update accepts the same forms as
+// UpdateById and is validated against the document currently matching the filter.
+// When an upsert inserts a document and ReturnBefore is set, ErrNotFound is returned.
+func (m *Model) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, result interface{}, opts ...FindOneAndOptions) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+	o := findOneAndOptions(opts)
+
+	filterMap, err := toFilterMap(filter)
+	if err != nil {
+		return err
+	}
+
+	// Prepare update data (handle timestamps and insert-only fields)
+	finalUpdate, err := m.prepareUpdate(update)
+	if err != nil {
+		return err
+	}
+	if o.Upsert {
+		m.addInsertOnlyFields(finalUpdate, filterMap)
+	}
+
+	// Run pre-update hooks, which may scope the filter or change the update
+	hc := m.newHookContext(ctx, "update", "FindOneAndUpdate")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
+	// Validate the document as it will look after the update
+	matched, _, err := m.simulateUpdate(ctx, hc, o.Sort, o.Upsert)
+	if err != nil {
+		return err
+	}
+
+	// Only update the document that was validated, at the validated version
+	writeFilter, writeUpdate, upsert := m.upsertWrite(hc.Filter, hc.Update, matched, o.Upsert)
+
+	// Apply the update and read the document in a single operation
+	updateOpts := options.FindOneAndUpdate().
+		SetReturnDocument(o.returnDocument()).
+		SetUpsert(upsert)
+	if o.Sort != nil {
+		updateOpts.SetSort(o.Sort)
+	}
+
+	err = m.Collection.FindOneAndUpdate(ctx, writeFilter, writeUpdate, updateOpts).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if matched != nil {
+				return m.matchFailure(ctx, hc.Filter, formatID(matched["_id"]))
+			}
+			log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+			return errors.ErrNotFound
+		}
+		log.Printf("⚠️ Failed to update document: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "failed to update document")
+	}
+
+	// Apply post-update middlewares with the returned document
+	return m.runPostHooks(hc, result)
+}
+
+// FindOneAndReplace atomically replaces a single document matching the filter and decodes
+// it into result, after the replacement by default. On schemas with timestamps the
+// replaced document keeps its createdAt and gets a new updatedAt. On versioned schemas
+// the replacement gets the next version, and a replacement carrying a version fails with
+// ErrVersionConflict unless it is the stored one.
+func (m *Model) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{}, result interface{}, opts ...FindOneAndOptions) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+	o := findOneAndOptions(opts)
+
+	filterMap, err := toFilterMap(filter)
+	if err != nil {
+		return err
+	}
+
+	replacementDoc, err := toFilterMap(replacement)
+	if err != nil {
+		return errors.WithDetails(errors.ErrValidation, "replacement must be a document")
+	}
+
+	// Run pre-update hooks, which may scope the filter or change the replacement
+	hc := m.newHookContext(ctx, "update", "FindOneAndReplace")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Document = replacementDoc
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
+	// Validate the replacement against the document currently matching the filter
+	existingDoc, _, err := m.prepareReplacement(ctx, hc, replacementDoc, o.Sort, o.Upsert)
+	if err != nil {
+		return err
+	}
+
+	// Replace the document and read it in a single operation. A matched document is
+	// never upserted, so a concurrent change fails instead of inserting a copy.
+	replaceOpts := options.FindOneAndReplace().
+		SetReturnDocument(o.returnDocument()).
+		SetUpsert(o.Upsert && existingDoc == nil)
+	if o.Sort != nil {
+		replaceOpts.SetSort(o.Sort)
+	}
+
+	// Only replace the document that was validated, at the validated version
+	err = m.Collection.FindOneAndReplace(ctx, m.pinFilter(hc.Filter, existingDoc), replacementDoc, replaceOpts).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if existingDoc != nil {
+				return m.matchFailure(ctx, hc.Filter, formatID(existingDoc["_id"]))
+			}
+			log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+			return errors.ErrNotFound
+		}
+		log.Printf("⚠️ Failed to replace document: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "failed to replace document")
+	}
+
+	// Apply post-update middlewares with the returned document
+	return m.runPostHooks(hc, result)
+}
+
+// prepareReplacement keeps the creation time of the document matching the filter of the
+// hook context in the replacement and, on versioned schemas, sets its version to the
+// next one, then runs the pre-update middlewares and validation on it. A replacement
+// carrying a version other than the stored one fails with ErrVersionConflict. It returns the matched document, nil when an upsert would insert the replacement,
+// together with the validated replacement.
+func (m *Model) prepareReplacement(ctx context.Context, hc *schema.HookContext, replacementDoc bson.M, sort interface{}, upsert bool) (bson.M, interface{}, error) {
+	// Find the existing document, so its creation time can be kept
+	findOpts := options.FindOne()
+	if sort != nil {
+		findOpts.SetSort(sort)
+	}
+
+	var existingDoc bson.M
+	err := m.Collection.FindOne(ctx, hc.Filter, findOpts).Decode(&existingDoc)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("⚠️ Failed to retrieve document for replace: %v", err)
+		return nil, nil, errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
+	}
+	if err == mongo.ErrNoDocuments && !upsert {
+		log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+		return nil, nil, errors.ErrNotFound
+	}
+
+	// The replacement continues the stored version; an inserted one starts at 0
+	if m.versionEnabled() {
+		key := m.Schema.VersionKey
+		if existingDoc == nil {
+			replacementDoc[key] = 0
+		} else {
+			supplied, ok := replacementDoc[key]
+			if ok && !versionMatches(supplied, existingDoc[key]) {
+				id := formatID(existingDoc["_id"])
+				log.Printf("⚠️ Version conflict for document with ID: %s", id)
+				return nil, nil, errors.WrapWithID(errors.ErrVersionConflict, "document was modified", id)
+			}
+			replacementDoc[key] = nextVersion(existingDoc[key])
+		}
+	}
+
+	// Handle timestamps
+	if m.Schema != nil && m.Schema.Timestamps {
+		now := time.Now()
+		delete(replacementDoc, "CreatedAt")
+		delete(replacementDoc, "UpdatedAt")
+		replacementDoc["createdAt"] = now
+		if createdAt, ok := existingDoc["createdAt"]; ok {
+			replacementDoc["createdAt"] = createdAt
+		}
+		replacementDoc["updatedAt"] = now
+	}
+
+	// Transform strings, apply pre-update middlewares and validate the replacement
+	if m.Schema != nil {
+		m.Schema.ApplyTransforms(replacementDoc)
+	}
+	var updatedDoc interface{} = replacementDoc
+	if m.Schema != nil && m.Schema.ModelType != nil {
+		newInstance, err := m.toModelInstance(replacementDoc)
+		if err != nil {
+			log.Printf("⚠️ Failed to convert document to struct for validation: %v", err)
+			return nil, nil, errors.Wrap(errors.ErrDecoding, "failed to convert to struct for validation")
+		}
+		updatedDoc = newInstance
+	}
+
+	if err := m.applyMiddlewares("update", updatedDoc); err != nil {
+		return nil, nil, err
+	}
+
+	if m.Schema != nil {
+		if err := m.runValidateHooks(ctx, hc.Operation, updatedDoc); err != nil {
+			return nil, nil, err
+		}
+
+		if err := m.Schema.ValidateDocument(updatedDoc); err != nil {
+			log.Printf("⚠️ Document validation failed: %v", err)
+			return nil, nil, err
+		}
+	}
+
+	return existingDoc, updatedDoc, nil
+}
+
+// FindOneAndDelete atomically deletes a single document matching the filter and decodes
+// the deleted document into result. On soft-delete schemas the document is marked as
+// deleted instead, and the returned document includes the mark. When pre-delete
+// middlewares check the document, only that document is deleted: if it changed
+// meanwhile, ErrVersionConflict is returned on versioned schemas and ErrNotFound
+// otherwise.
+func (m *Model) FindOneAndDelete(ctx context.Context, filter interface{}, result interface{}, opts ...FindOneAndOptions) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+	o := findOneAndOptions(opts)
+
+	filterMap, err := toFilterMap(filter)
+	if err != nil {
+		return err
+	}
+
+	// Run pre-delete hooks, which may scope the filter
+	hc := m.newHookContext(ctx, "delete", "FindOneAndDelete")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
+	// Pre-delete middlewares receive the document about to be deleted
+	var existingDoc bson.M
+	if m.Schema != nil && len(m.Schema.Middlewares["delete"]) > 0 {
+		findOpts := options.FindOne()
+		if o.Sort != nil {
+			findOpts.SetSort(o.Sort)
+		}
+
+		err = m.Collection.FindOne(ctx, hc.Filter, findOpts).Decode(&existingDoc)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+				return errors.ErrNotFound
+			}
+			log.Printf("⚠️ Failed to retrieve document for delete: %v", err)
+			return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
+		}
+
+		if err := m.applyMiddlewares("delete", m.documentPayload(existingDoc)); err != nil {
+			return err
+		}
+	}
+
+	// Only delete the document the middlewares checked, at the checked version
+	deleteFilter := m.pinFilter(hc.Filter, existingDoc)
+
+	// Delete, or mark the document as deleted on soft-delete schemas
+	var singleResult *mongo.SingleResult
+	if m.softDeleteEnabled() {
+		updateOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		if o.Sort != nil {
+			updateOpts.SetSort(o.Sort)
+		}
+		singleResult = m.Collection.FindOneAndUpdate(ctx, deleteFilter, m.softDeleteUpdate(), updateOpts)
+	} else {
+		deleteOpts := options.FindOneAndDelete()
+		if o.Sort != nil {
+			deleteOpts.SetSort(o.Sort)
+		}
+		singleResult = m.Collection.FindOneAndDelete(ctx, deleteFilter, deleteOpts)
+	}
+
+	if err := singleResult.Decode(result); err != nil {
+		if err == mongo.ErrNoDocuments {
+			if existingDoc != nil {
+				return m.matchFailure(ctx, hc.Filter, formatID(existingDoc["_id"]))
+			}
+			log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+			return errors.ErrNotFound
+		}
+		log.Printf("⚠️ Failed to delete document: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "failed to delete document")
+	}
+
+	// Apply post-delete middlewares with the deleted document
+	return m.runPostHooks(hc, result)
+}
+
+// FindOneAndUpdate atomically updates a single document with type safety
+func (m *GenericModel[T]) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...FindOneAndOptions) (*T, error) {
+	result := new(T)
+	if err := m.Model.FindOneAndUpdate(ctx, filter, update, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindOneAndReplace atomically replaces a single document with type safety
+func (m *GenericModel[T]) FindOneAndReplace(ctx context.Context, filter interface{}, replacement *T, opts ...FindOneAndOptions) (*T, error) {
+	result := new(T)
+	if err := m.Model.FindOneAndReplace(ctx, filter, replacement, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindOneAndDelete atomically deletes a single document with type safety
+func (m *GenericModel[T]) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...FindOneAndOptions) (*T, error) {
+	result := new(T)
+	if err := m.Model.FindOneAndDelete(ctx, filter, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
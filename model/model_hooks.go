@@ -0,0 +1,174 @@
+package model
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newHookContext creates the hook context passed to context-aware middlewares
+func (m *Model) newHookContext(ctx context.Context, event, operation string) *schema.HookContext {
+	return &schema.HookContext{
+		Ctx:       ctx,
+		Model:     m.Name,
+		Operation: operation,
+		Event:     event,
+		Session:   mongo.SessionFromContext(ctx),
+	}
+}
+
+// hasHooks reports whether any context-aware middleware or post middleware is registered for the event
+func (m *Model) hasHooks(event string) bool {
+	if m.Schema == nil {
+		return false
+	}
+	return len(m.Schema.PreHooks[event]) > 0 || m.hasPostHooks(event)
+}
+
+// hasPostHooks reports whether any post middleware is registered for the event
+func (m *Model) hasPostHooks(event string) bool {
+	if m.Schema == nil {
+		return false
+	}
+	return len(m.Schema.PostMiddlewares[event]) > 0 || len(m.Schema.PostHooks[event]) > 0
+}
+
+// runPreHooks applies context-aware pre middlewares for the event of the hook context
+func (m *Model) runPreHooks(hc *schema.HookContext) error {
+	if m.Schema == nil {
+		return nil
+	}
+
+	for _, hook := range m.Schema.PreHooks[hc.Event] {
+		if err := hook(hc); err != nil {
+			return errors.Wrap(errors.ErrMiddleware, err.Error())
+		}
+	}
+	return nil
+}
+
+// prepareFilter applies soft-delete scoping to a caller supplied filter and runs the pre
+// middlewares of the operation. It returns the filter to use, which reflects any changes
+// made by the hooks.
+func (m *Model) prepareFilter(hc *schema.HookContext, filter interface{}, scope query.DeletedScope) (interface{}, error) {
+	if !m.softDeleteEnabled() && !m.hasHooks(hc.Event) {
+		return filter, nil
+	}
+
+	filterMap, err := toFilterMap(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hc.Filter = m.applySoftDelete(filterMap, scope)
+	if err := m.runPreHooks(hc); err != nil {
+		return nil, err
+	}
+
+	return hc.Filter, nil
+}
+
+// runPostHooks applies post middlewares and context-aware post middlewares after a successful operation
+func (m *Model) runPostHooks(hc *schema.HookContext, result interface{}) error {
+	if m.Schema == nil {
+		return nil
+	}
+
+	for _, middleware := range m.Schema.PostMiddlewares[hc.Event] {
+		if err := middleware(result); err != nil {
+			return errors.Wrap(errors.ErrMiddleware, err.Error())
+		}
+	}
+
+	hc.Result = result
+	for _, hook := range m.Schema.PostHooks[hc.Event] {
+		if err := hook(hc); err != nil {
+			return errors.Wrap(errors.ErrMiddleware, err.Error())
+		}
+	}
+	return nil
+}
+
+// runValidateHooks applies pre-validate middlewares to a document about to be validated
+func (m *Model) runValidateHooks(ctx context.Context, operation string, doc interface{}) error {
+	if err := m.applyMiddlewares("validate", doc); err != nil {
+		return err
+	}
+
+	hc := m.newHookContext(ctx, "validate", operation)
+	hc.Document = doc
+	return m.runPreHooks(hc)
+}
+
+// toModelInstance converts a raw document into a new instance of the schema's model type
+func (m *Model) toModelInstance(doc bson.M) (interface{}, error) {
+	docType := reflect.TypeOf(m.Schema.ModelType).Elem()
+	instance := reflect.New(docType).Interface()
+
+	bytes, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(bytes, instance); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// documentPayload converts a raw document into an instance of the model type when one
+// is known, so middlewares receive the same document type that Create receives
+func (m *Model) documentPayload(doc bson.M) interface{} {
+	if m.Schema == nil || m.Schema.ModelType == nil {
+		return doc
+	}
+
+	instance, err := m.toModelInstance(doc)
+	if err != nil {
+		return doc
+	}
+
+	return instance
+}
+
+// toFilterMap converts a filter into a bson.M so hooks can inspect and modify it
+func toFilterMap(filter interface{}) (bson.M, error) {
+	switch f := filter.(type) {
+	case nil:
+		return bson.M{}, nil
+	case bson.M:
+		return cloneFilter(f), nil
+	case map[string]interface{}:
+		return cloneFilter(f), nil
+	case bson.D:
+		filterMap := bson.M{}
+		for _, e := range f {
+			filterMap[e.Key] = e.Value
+		}
+		return filterMap, nil
+	default:
+		bytes, err := bson.Marshal(filter)
+		if err != nil {
+			return nil, errors.WithDetails(errors.ErrValidation, "filter must be a document")
+		}
+		var filterMap bson.M
+		if err := bson.Unmarshal(bytes, &filterMap); err != nil {
+			return nil, errors.WithDetails(errors.ErrValidation, "filter must be a document")
+		}
+		return filterMap, nil
+	}
+}
+
+// cloneFilter returns a shallow copy of a filter, so hooks do not modify the caller's query builder
+func cloneFilter(filter bson.M) bson.M {
+	clone := make(bson.M, len(filter))
+	for k, v := range filter {
+		clone[k] = v
+	}
+	return clone
+}
@@ -0,0 +1,324 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SyncIndexesOptions configures SyncIndexes
+type SyncIndexesOptions struct {
+	// DropStale drops the indexes of the collection the schema doesn't declare, and
+	// recreates the declared indexes whose existing definition differs
+	DropStale bool
+	// DryRun only logs the plan, without changing the indexes of the collection
+	DryRun bool
+}
+
+// IndexDiff describes how the indexes of a collection differ from those the schema
+// declares, and the changes SyncIndexes made or, in dry-run mode, would make
+type IndexDiff struct {
+	// Created lists the declared indexes missing from the collection
+	Created []string
+	// Changed lists the declared indexes whose existing index has other keys or
+	// options. They are dropped and created again with DropStale.
+	Changed []string
+	// Stale lists the indexes of the collection the schema doesn't declare.
+	// They are dropped with DropStale.
+	Stale []string
+	// Unchanged lists the declared indexes that already exist as declared
+	Unchanged []string
+}
+
+// HasChanges reports whether the indexes of the collection differ from the schema
+func (d *IndexDiff) HasChanges() bool {
+	return len(d.Created) > 0 || len(d.Changed) > 0 || len(d.Stale) > 0
+}
+
+// String lists the differences, one index per line: "+" for indexes to create,
+// "~" for indexes to recreate and "-" for stale indexes
+func (d *IndexDiff) String() string {
+	var lines []string
+	for _, name := range d.Created {
+		lines = append(lines, "+ "+name)
+	}
+	for _, name := range d.Changed {
+		lines = append(lines, "~ "+name)
+	}
+	for _, name := range d.Stale {
+		lines = append(lines, "- "+name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SyncIndexes compares the indexes the schema declares with those of the collection.
+// It creates the missing indexes and, with DropStale, drops the indexes the schema
+// doesn't declare and recreates the changed ones. The default _id index is never
+// dropped. The returned diff lists the differences found; in dry-run mode they are
+// logged and left as they are.
+func (m *Model) SyncIndexes(ctx context.Context, opts ...SyncIndexesOptions) (*IndexDiff, error) {
+	if m.Collection == nil {
+		return nil, errors.ErrNilCollection
+	}
+
+	var o SyncIndexesOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	existing, err := m.listIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &IndexDiff{}
+	var create []schema.Index
+	declared := make(map[string]bool)
+	if m.Schema != nil {
+		for _, index := range m.Schema.AllIndexes() {
+			name := index.IndexName()
+			declared[name] = true
+
+			current, exists := existing[name]
+			switch {
+			case !exists:
+				diff.Created = append(diff.Created, name)
+				create = append(create, index)
+			case !indexMatches(index, current):
+				diff.Changed = append(diff.Changed, name)
+				if o.DropStale {
+					create = append(create, index)
+				}
+			default:
+				diff.Unchanged = append(diff.Unchanged, name)
+			}
+		}
+	}
+
+	for name := range existing {
+		if name != "_id_" && !declared[name] {
+			diff.Stale = append(diff.Stale, name)
+		}
+	}
+	sort.Strings(diff.Stale)
+
+	if o.DryRun {
+		if diff.HasChanges() {
+			log.Printf("📋 Index plan for collection '%s':\n%s", m.Collection.Name(), diff)
+		} else {
+			log.Printf("📋 Indexes of collection '%s' are in sync", m.Collection.Name())
+		}
+		return diff, nil
+	}
+
+	// Drop stale and changed indexes before creating their replacements
+	if o.DropStale {
+		for _, name := range append(append([]string{}, diff.Stale...), diff.Changed...) {
+			if _, err := m.Collection.Indexes().DropOne(ctx, name); err != nil {
+				return diff, errors.Wrap(errors.ErrDatabase, fmt.Sprintf("failed to drop index '%s': %v", name, err))
+			}
+			log.Printf("🗑️ Dropped index '%s'", name)
+		}
+	} else if len(diff.Changed) > 0 {
+		log.Printf("⚠️ Indexes %v of collection '%s' differ from the schema; sync with DropStale to recreate them",
+			diff.Changed, m.Collection.Name())
+	}
+
+	// Create every index, even when one of them fails
+	var failures []string
+	for _, index := range create {
+		if _, err := m.Collection.Indexes().CreateOne(ctx, indexModel(index)); err != nil {
+			failures = append(failures, fmt.Sprintf("index '%s': %v", index.IndexName(), err))
+			continue
+		}
+		log.Printf("✅ Created index '%s'", index.IndexName())
+	}
+	if len(failures) > 0 {
+		return diff, errors.Wrap(errors.ErrDatabase, "failed to create "+strings.Join(failures, "; "))
+	}
+
+	return diff, nil
+}
+
+// listIndexes returns the indexes of the collection by name, as listed by the server
+func (m *Model) listIndexes(ctx context.Context) (map[string]bson.Raw, error) {
+	cursor, err := m.Collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrDatabase, err.Error())
+	}
+
+	var specs []bson.Raw
+	if err := cursor.All(ctx, &specs); err != nil {
+		return nil, errors.Wrap(errors.ErrDecoding, err.Error())
+	}
+
+	indexes := make(map[string]bson.Raw, len(specs))
+	for _, spec := range specs {
+		if name, ok := spec.Lookup("name").StringValueOK(); ok {
+			indexes[name] = spec
+		}
+	}
+	return indexes, nil
+}
+
+// indexMatches reports whether an existing index, as listed by the server, has the
+// keys and options of a declared index
+func indexMatches(index schema.Index, raw bson.Raw) bool {
+	var spec bson.M
+	if err := bson.Unmarshal(raw, &spec); err != nil {
+		return false
+	}
+	if !indexKeysMatch(index, raw, spec) {
+		return false
+	}
+	if index.Unique != (spec["unique"] == true) || index.Sparse != (spec["sparse"] == true) {
+		return false
+	}
+
+	expireAfter, hasExpire := spec["expireAfterSeconds"]
+	if (index.ExpireAfterSeconds != nil) != hasExpire ||
+		hasExpire && !sameIndexValue(*index.ExpireAfterSeconds, expireAfter) {
+		return false
+	}
+
+	partial, hasPartial := spec["partialFilterExpression"]
+	if (len(index.PartialFilter) > 0) != hasPartial ||
+		hasPartial && !sameIndexValue(index.PartialFilter, partial) {
+		return false
+	}
+
+	// The server fills in the collation defaults, so only the locale is compared
+	collation, _ := spec["collation"].(bson.M)
+	if index.Collation == nil {
+		return collation == nil
+	}
+	return collation != nil && collation["locale"] == index.Collation.Locale
+}
+
+// indexKeysMatch reports whether an existing index has the keys of a declared index.
+// Text indexes are listed with internal keys, so their fields are compared by weight.
+func indexKeysMatch(index schema.Index, raw bson.Raw, spec bson.M) bool {
+	keys, _ := spec["key"].(bson.M)
+	if _, isText := keys["_fts"]; isText {
+		weights, _ := spec["weights"].(bson.M)
+		textFields := 0
+		for _, key := range index.Keys {
+			if key.Value != schema.IndexText {
+				continue
+			}
+			textFields++
+			weight := interface{}(1)
+			if w, exists := index.Weights[key.Key]; exists {
+				weight = w
+			}
+			if !sameIndexValue(weight, weights[key.Key]) {
+				return false
+			}
+		}
+		return textFields > 0 && textFields == len(weights)
+	}
+
+	// Keys are decoded into a bson.D to compare their order
+	var ordered bson.D
+	keyDoc, ok := raw.Lookup("key").DocumentOK()
+	if !ok || bson.Unmarshal(keyDoc, &ordered) != nil {
+		return false
+	}
+	if len(ordered) != len(index.Keys) {
+		return false
+	}
+	for i, key := range index.Keys {
+		if ordered[i].Key != key.Key || !sameIndexValue(key.Value, ordered[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameIndexValue reports whether a declared value equals a value listed by the server.
+// Numbers are compared by value, as the server may list them as int32, int64 or double.
+func sameIndexValue(declared, listed interface{}) bool {
+	return reflect.DeepEqual(normalizeIndexValue(declared), normalizeIndexValue(listed))
+}
+
+// normalizeIndexValue converts the numbers of a value to float64 and its documents to bson.M
+func normalizeIndexValue(value interface{}) interface{} {
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		return val.Float()
+	}
+
+	switch v := value.(type) {
+	case bson.D:
+		normalized := make(bson.M, len(v))
+		for _, e := range v {
+			normalized[e.Key] = normalizeIndexValue(e.Value)
+		}
+		return normalized
+	case bson.M:
+		normalized := make(bson.M, len(v))
+		for key, e := range v {
+			normalized[key] = normalizeIndexValue(e)
+		}
+		return normalized
+	case map[string]interface{}:
+		return normalizeIndexValue(bson.M(v))
+	case bson.A:
+		normalized := make(bson.A, len(v))
+		for i, e := range v {
+			normalized[i] = normalizeIndexValue(e)
+		}
+		return normalized
+	case []interface{}:
+		return normalizeIndexValue(bson.A(v))
+	}
+	return value
+}
+
+// indexModel converts an index declared by a schema into the driver's index model
+func indexModel(index schema.Index) mongo.IndexModel {
+	indexOptions := options.Index()
+	if index.Name != "" {
+		indexOptions.SetName(index.Name)
+	}
+	if index.Unique {
+		indexOptions.SetUnique(true)
+	}
+	if index.Sparse {
+		indexOptions.SetSparse(true)
+	}
+	if index.ExpireAfterSeconds != nil {
+		indexOptions.SetExpireAfterSeconds(*index.ExpireAfterSeconds)
+	}
+	if len(index.Weights) > 0 {
+		indexOptions.SetWeights(index.Weights)
+	}
+	if index.DefaultLanguage != "" {
+		indexOptions.SetDefaultLanguage(index.DefaultLanguage)
+	}
+	if len(index.PartialFilter) > 0 {
+		indexOptions.SetPartialFilterExpression(index.PartialFilter)
+	}
+	if index.Collation != nil {
+		indexOptions.SetCollation(index.Collation)
+	}
+
+	return mongo.IndexModel{
+		Keys:    index.Keys,
+		Options: indexOptions,
+	}
+}
@@ -0,0 +1,358 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// registeredModel is implemented by Model and, through embedding, by GenericModel,
+// so references can be resolved through either kind of registered model
+type registeredModel interface {
+	base() *Model
+}
+
+// base returns the model itself
+func (m *Model) base() *Model {
+	return m
+}
+
+// refModel returns the model registered for references on the model's connection under
+// the given name
+func (m *Model) refModel(ref string) (*Model, error) {
+	if m.Client == nil {
+		return nil, errors.WithDetails(errors.ErrValidation,
+			fmt.Sprintf("model '%s' has no connection to resolve references with", m.Name))
+	}
+
+	registered, ok := m.Client.GetRefModel(ref).(registeredModel)
+	if !ok {
+		return nil, errors.WithDetails(errors.ErrValidation, fmt.Sprintf("referenced model '%s' is not registered", ref))
+	}
+	return registered.base(), nil
+}
+
+// Populate resolves the referenced documents of the given fields in results, which may be
+// a document or a slice of documents as decoded by the find methods. The fields must
+// declare a Ref in the schema. Referenced documents are loaded with one query per
+// referenced model and stored in the struct field tagged `schema:"populate=<field>"`,
+// or in place of the reference in bson.M documents.
+func (m *Model) Populate(ctx context.Context, results interface{}, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	docs, err := populateTargets(results)
+	if err != nil {
+		return err
+	}
+
+	// Group the paths by referenced model
+	refPaths := make(map[string][]string)
+	var refs []string
+	for _, path := range paths {
+		var field schema.Field
+		if m.Schema != nil {
+			field = m.Schema.Fields[path]
+		}
+		if field.Ref == "" {
+			return errors.WithDetails(errors.ErrValidation, fmt.Sprintf("field '%s' is not a reference", path))
+		}
+		if _, exists := refPaths[field.Ref]; !exists {
+			refs = append(refs, field.Ref)
+		}
+		refPaths[field.Ref] = append(refPaths[field.Ref], path)
+	}
+
+	for _, ref := range refs {
+		refModel, err := m.refModel(ref)
+		if err != nil {
+			return err
+		}
+
+		// Collect the referenced IDs of every document
+		var ids bson.A
+		seen := make(map[primitive.ObjectID]bool)
+		for _, doc := range docs {
+			for _, path := range refPaths[ref] {
+				docIDs, err := doc.refIDs(path)
+				if err != nil {
+					return err
+				}
+				for _, id := range docIDs {
+					if !seen[id] {
+						seen[id] = true
+						ids = append(ids, id)
+					}
+				}
+			}
+		}
+
+		// Load the referenced documents with a single query
+		found := make(map[primitive.ObjectID]bson.M)
+		if len(ids) > 0 {
+			var refDocs []bson.M
+			if err := refModel.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, &refDocs); err != nil {
+				return err
+			}
+			for _, refDoc := range refDocs {
+				if id, ok := refDoc["_id"].(primitive.ObjectID); ok {
+					found[id] = refDoc
+				}
+			}
+		}
+
+		for _, doc := range docs {
+			for _, path := range refPaths[ref] {
+				if err := doc.populate(path, found); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// populateTarget is a document whose references are resolved
+type populateTarget interface {
+	// refIDs returns the IDs referenced by a field
+	refIDs(path string) ([]primitive.ObjectID, error)
+	// populate stores the referenced documents of a field
+	populate(path string, found map[primitive.ObjectID]bson.M) error
+}
+
+// populateTargets returns the documents held by a find result
+func populateTargets(results interface{}) ([]populateTarget, error) {
+	switch r := results.(type) {
+	case nil:
+		return nil, nil
+	case bson.M:
+		return []populateTarget{mapTarget(r)}, nil
+	case map[string]interface{}:
+		return []populateTarget{mapTarget(r)}, nil
+	}
+
+	val := reflect.ValueOf(results)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, nil
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		if !val.CanAddr() {
+			return nil, errors.WithDetails(errors.ErrValidation, "results must be a pointer to populate")
+		}
+		return []populateTarget{structTarget{val}}, nil
+	case reflect.Map:
+		if docMap, ok := val.Interface().(bson.M); ok {
+			return []populateTarget{mapTarget(docMap)}, nil
+		}
+		if docMap, ok := val.Interface().(map[string]interface{}); ok {
+			return []populateTarget{mapTarget(docMap)}, nil
+		}
+	case reflect.Slice:
+		var targets []populateTarget
+		for i := 0; i < val.Len(); i++ {
+			itemTargets, err := populateTargets(val.Index(i).Addr().Interface())
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, itemTargets...)
+		}
+		return targets, nil
+	}
+
+	return nil, errors.WithDetails(errors.ErrValidation, "results must be documents to populate")
+}
+
+// structTarget resolves references of a struct document
+type structTarget struct {
+	val reflect.Value
+}
+
+func (s structTarget) refIDs(path string) ([]primitive.ObjectID, error) {
+	field, ok := fieldByBsonName(s.val, path)
+	if !ok {
+		return nil, nil
+	}
+
+	switch v := field.Interface().(type) {
+	case primitive.ObjectID:
+		if v.IsZero() {
+			return nil, nil
+		}
+		return []primitive.ObjectID{v}, nil
+	case *primitive.ObjectID:
+		if v == nil || v.IsZero() {
+			return nil, nil
+		}
+		return []primitive.ObjectID{*v}, nil
+	case []primitive.ObjectID:
+		return v, nil
+	default:
+		return nil, errors.WithDetails(errors.ErrValidation,
+			fmt.Sprintf("field '%s' must be an ObjectID or []ObjectID to populate", path))
+	}
+}
+
+func (s structTarget) populate(path string, found map[primitive.ObjectID]bson.M) error {
+	target, ok := populateField(s.val, path)
+	if !ok {
+		return errors.WithDetails(errors.ErrValidation,
+			fmt.Sprintf("no field tagged populate=%s in %s", path, s.val.Type().Name()))
+	}
+
+	ids, err := s.refIDs(path)
+	if err != nil {
+		return err
+	}
+
+	// A list of references fills a slice, a single reference a document
+	field, _ := fieldByBsonName(s.val, path)
+	if field.Kind() == reflect.Slice {
+		if target.Kind() != reflect.Slice {
+			return errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("field populated from '%s' must be a slice", path))
+		}
+		items := reflect.MakeSlice(target.Type(), 0, len(ids))
+		for _, id := range ids {
+			doc, exists := found[id]
+			if !exists {
+				continue
+			}
+			item, err := decodeAs(doc, target.Type().Elem())
+			if err != nil {
+				return err
+			}
+			items = reflect.Append(items, item)
+		}
+		target.Set(items)
+		return nil
+	}
+
+	if len(ids) == 0 || found[ids[0]] == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	item, err := decodeAs(found[ids[0]], target.Type())
+	if err != nil {
+		return err
+	}
+	target.Set(item)
+	return nil
+}
+
+// mapTarget resolves references of a bson.M document in place
+type mapTarget bson.M
+
+func (d mapTarget) refIDs(path string) ([]primitive.ObjectID, error) {
+	switch v := d[path].(type) {
+	case nil:
+		return nil, nil
+	case primitive.ObjectID:
+		return []primitive.ObjectID{v}, nil
+	case []primitive.ObjectID:
+		return v, nil
+	case bson.A, []interface{}:
+		items := reflect.ValueOf(v)
+		ids := make([]primitive.ObjectID, 0, items.Len())
+		for i := 0; i < items.Len(); i++ {
+			if id, ok := items.Index(i).Interface().(primitive.ObjectID); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (d mapTarget) populate(path string, found map[primitive.ObjectID]bson.M) error {
+	ids, err := d.refIDs(path)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	if _, single := d[path].(primitive.ObjectID); single {
+		if doc, exists := found[ids[0]]; exists {
+			d[path] = doc
+		} else {
+			d[path] = nil
+		}
+		return nil
+	}
+
+	docs := bson.A{}
+	for _, id := range ids {
+		if doc, exists := found[id]; exists {
+			docs = append(docs, doc)
+		}
+	}
+	d[path] = docs
+	return nil
+}
+
+// fieldByBsonName returns the struct field stored under the given name
+func fieldByBsonName(val reflect.Value, name string) (reflect.Value, bool) {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fieldName := strings.Split(t.Field(i).Tag.Get("bson"), ",")[0]
+		if fieldName == "" {
+			fieldName = t.Field(i).Name
+		}
+		if fieldName == name {
+			return val.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// populateField returns the struct field tagged to receive the documents referenced by a field
+func populateField(val reflect.Value, path string) (reflect.Value, bool) {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		for _, opt := range strings.Split(t.Field(i).Tag.Get("schema"), ",") {
+			if strings.TrimSpace(opt) == "populate="+path && val.Field(i).CanSet() {
+				return val.Field(i), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// decodeAs converts a raw document into a value of the given type
+func decodeAs(doc bson.M, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface {
+		return reflect.ValueOf(doc), nil
+	}
+
+	ptr := t.Kind() == reflect.Ptr
+	elemType := t
+	if ptr {
+		elemType = t.Elem()
+	}
+
+	bytes, err := bson.Marshal(doc)
+	if err != nil {
+		return reflect.Value{}, errors.Wrap(errors.ErrDecoding, err.Error())
+	}
+	item := reflect.New(elemType)
+	if err := bson.Unmarshal(bytes, item.Interface()); err != nil {
+		return reflect.Value{}, errors.Wrap(errors.ErrDecoding, err.Error())
+	}
+
+	if ptr {
+		return item, nil
+	}
+	return item.Elem(), nil
+}
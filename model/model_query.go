@@ -4,10 +4,9 @@ import (
 	"context"
 	"github.com/isimtekin/merhongo/errors"
 	"github.com/isimtekin/merhongo/query"
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
-	"reflect"
 )
 
 // FindWithQuery finds documents using a query builder
@@ -23,8 +22,15 @@ func (m *Model) FindWithQuery(ctx context.Context, queryBuilder *query.Builder,
 		return errors.Wrap(err, "failed to build query")
 	}
 
+	// Run pre-find hooks, which may modify the filter
+	hc := m.newHookContext(ctx, "find", "FindWithQuery")
+	hc.Filter = m.applySoftDelete(cloneFilter(filter), queryBuilder.DeletedScope())
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
 	// Execute the query
-	cursor, err := m.Collection.Find(ctx, filter, options)
+	cursor, err := m.Collection.Find(ctx, hc.Filter, options)
 	if err != nil {
 		log.Printf("⚠️ Failed to retrieve documents with query: %v", err)
 		return errors.Wrap(errors.ErrDatabase, "failed to retrieve documents")
@@ -42,7 +48,12 @@ func (m *Model) FindWithQuery(ctx context.Context, queryBuilder *query.Builder,
 		return errors.Wrap(errors.ErrDecoding, err.Error())
 	}
 
-	return nil
+	// Resolve referenced documents
+	if err := m.Populate(ctx, results, queryBuilder.PopulatePaths()...); err != nil {
+		return err
+	}
+
+	return m.runPostHooks(hc, results)
 }
 
 // FindOneWithQuery finds a single document using a query builder
@@ -59,6 +70,14 @@ func (m *Model) FindOneWithQuery(ctx context.Context, queryBuilder *query.Builde
 		return errors.Wrap(err, "failed to build query")
 	}
 
+	// Run pre-find hooks, which may modify the filter
+	hc := m.newHookContext(ctx, "find", "FindOneWithQuery")
+	hc.Filter = m.applySoftDelete(cloneFilter(filter), queryBuilder.DeletedScope())
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+	filter = hc.Filter
+
 	// Create FindOneOptions from the parts we need
 	findOneOpts := options.FindOne()
 
@@ -106,7 +125,12 @@ func (m *Model) FindOneWithQuery(ctx context.Context, queryBuilder *query.Builde
 		return errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
 	}
 
-	return nil
+	// Resolve referenced documents
+	if err := m.Populate(ctx, result, queryBuilder.PopulatePaths()...); err != nil {
+		return err
+	}
+
+	return m.runPostHooks(hc, result)
 }
 
 // CountWithQuery counts documents using a query builder
@@ -122,8 +146,15 @@ func (m *Model) CountWithQuery(ctx context.Context, queryBuilder *query.Builder)
 		return 0, errors.Wrap(err, "failed to build query")
 	}
 
+	// Run pre-count hooks, which may modify the filter
+	hc := m.newHookContext(ctx, "count", "CountWithQuery")
+	hc.Filter = m.applySoftDelete(cloneFilter(filter), queryBuilder.DeletedScope())
+	if err := m.runPreHooks(hc); err != nil {
+		return 0, err
+	}
+
 	// Execute the count
-	count, err := m.Collection.CountDocuments(ctx, filter)
+	count, err := m.Collection.CountDocuments(ctx, hc.Filter)
 	if err != nil {
 		log.Printf("⚠️ Failed to count documents: %v", err)
 		return 0, errors.Wrap(errors.ErrDatabase, "failed to count documents")
@@ -152,61 +183,40 @@ func (m *Model) UpdateWithQuery(ctx context.Context, queryBuilder *query.Builder
 		return 0, err
 	}
 
-	// Validate affected documents if schema and model type are available
-	if m.Schema != nil && m.Schema.ModelType != nil {
-		// Find documents that will be affected
-		cursor, err := m.Collection.Find(ctx, filter)
-		if err != nil {
-			log.Printf("⚠️ Failed to retrieve documents for validation: %v", err)
-			return 0, errors.Wrap(errors.ErrDatabase, "failed to retrieve documents for validation")
-		}
-		defer cursor.Close(ctx)
-
-		// Validate each document
-		for cursor.Next(ctx) {
-			var existingDoc bson.M
-			if err := cursor.Decode(&existingDoc); err != nil {
-				log.Printf("⚠️ Failed to decode document for validation: %v", err)
-				return 0, errors.Wrap(errors.ErrDecoding, "failed to decode document")
-			}
-
-			// Apply update data to the existing document
-			for key, value := range finalUpdate {
-				existingDoc[key] = value
-			}
-
-			// Create a new instance of the model type
-			docType := reflect.TypeOf(m.Schema.ModelType).Elem()
-			newInstance := reflect.New(docType).Interface()
-
-			// Convert existingDoc to struct
-			bytes, _ := bson.Marshal(existingDoc)
-			if err := bson.Unmarshal(bytes, newInstance); err != nil {
-				log.Printf("⚠️ Failed to convert to struct for validation: %v", err)
-				return 0, errors.Wrap(errors.ErrDecoding, "failed to convert to struct for validation")
-			}
-
-			// Validate the full document
-			if err := m.Schema.ValidateDocument(newInstance); err != nil {
-				log.Printf("⚠️ Document validation failed: %v", err)
-				return 0, err
-			}
-		}
+	// Run pre-update hooks, which may modify the filter or the update
+	hc := m.newHookContext(ctx, "updateMany", "UpdateWithQuery")
+	hc.Filter = m.applySoftDelete(cloneFilter(filter), queryBuilder.DeletedScope())
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return 0, err
+	}
 
-		if err := cursor.Err(); err != nil {
-			log.Printf("⚠️ Error during cursor iteration: %v", err)
-			return 0, errors.Wrap(errors.ErrDatabase, "error during cursor iteration")
-		}
+	// Apply pre-updateMany middlewares with the filter, which they may modify
+	if err := m.applyMiddlewares("updateMany", hc.Filter); err != nil {
+		return 0, err
+	}
+	filter = hc.Filter
+	finalUpdate = hc.Update
+
+	// Validate affected documents
+	if err := m.validateUpdates(ctx, hc); err != nil {
+		return 0, err
 	}
 
 	// Apply the update with the validated data
-	updateDoc := map[string]interface{}{"$set": finalUpdate}
-	result, err := m.Collection.UpdateMany(ctx, filter, updateDoc)
+	result, err := m.Collection.UpdateMany(ctx, filter, finalUpdate)
 	if err != nil {
 		log.Printf("⚠️ Failed to update documents with query: %v", err)
 		return 0, errors.Wrap(errors.ErrDatabase, "failed to update documents")
 	}
 
+	// Apply post-update middlewares with the update result; multi-document
+	// updates share the "update" post event with UpdateById
+	hc.Event = "update"
+	if err := m.runPostHooks(hc, result); err != nil {
+		return result.ModifiedCount, err
+	}
+
 	return result.ModifiedCount, nil
 }
 
@@ -223,11 +233,40 @@ func (m *Model) DeleteWithQuery(ctx context.Context, queryBuilder *query.Builder
 		return 0, errors.Wrap(err, "failed to build query")
 	}
 
-	// Execute to delete
-	result, err := m.Collection.DeleteMany(ctx, filter)
-	if err != nil {
-		log.Printf("⚠️ Failed to delete documents with query: %v", err)
-		return 0, errors.Wrap(errors.ErrDatabase, "failed to delete documents")
+	// Run pre-delete hooks, which may modify the filter
+	hc := m.newHookContext(ctx, "deleteMany", "DeleteWithQuery")
+	hc.Filter = m.applySoftDelete(cloneFilter(filter), queryBuilder.DeletedScope())
+	if err := m.runPreHooks(hc); err != nil {
+		return 0, err
+	}
+
+	// Apply pre-deleteMany middlewares with the filter, which they may modify
+	if err := m.applyMiddlewares("deleteMany", hc.Filter); err != nil {
+		return 0, err
+	}
+
+	// Execute to delete, or mark the documents as deleted on soft-delete schemas
+	var result *mongo.DeleteResult
+	if m.softDeleteEnabled() {
+		updateResult, err := m.Collection.UpdateMany(ctx, hc.Filter, m.softDeleteUpdate())
+		if err != nil {
+			log.Printf("⚠️ Failed to soft-delete documents with query: %v", err)
+			return 0, errors.Wrap(errors.ErrDatabase, "failed to delete documents")
+		}
+		result = &mongo.DeleteResult{DeletedCount: updateResult.ModifiedCount}
+	} else {
+		result, err = m.Collection.DeleteMany(ctx, hc.Filter)
+		if err != nil {
+			log.Printf("⚠️ Failed to delete documents with query: %v", err)
+			return 0, errors.Wrap(errors.ErrDatabase, "failed to delete documents")
+		}
+	}
+
+	// Apply post-delete middlewares with the delete result; multi-document
+	// deletes share the "delete" post event with DeleteById
+	hc.Event = "delete"
+	if err := m.runPostHooks(hc, result); err != nil {
+		return result.DeletedCount, err
 	}
 
 	return result.DeletedCount, nil
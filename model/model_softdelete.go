@@ -0,0 +1,103 @@
+package model
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// softDeleteEnabled reports whether the model's schema uses soft-delete mode
+func (m *Model) softDeleteEnabled() bool {
+	return m.Schema != nil && m.Schema.SoftDelete
+}
+
+// softDeleteField returns the field marking soft-deleted documents
+func (m *Model) softDeleteField() string {
+	if m.Schema.SoftDeleteField == "" {
+		return schema.DefaultSoftDeleteField
+	}
+	return m.Schema.SoftDeleteField
+}
+
+// softDeleteUpdate returns the update document that marks documents as deleted
+func (m *Model) softDeleteUpdate() bson.M {
+	return bson.M{"$set": bson.M{m.softDeleteField(): time.Now()}}
+}
+
+// applySoftDelete adds the soft-delete condition for the given scope to a filter.
+// Filters that already constrain the soft-delete field are left untouched.
+func (m *Model) applySoftDelete(filter bson.M, scope query.DeletedScope) bson.M {
+	if !m.softDeleteEnabled() {
+		return filter
+	}
+
+	field := m.softDeleteField()
+	if _, exists := filter[field]; exists {
+		return filter
+	}
+
+	switch scope {
+	case query.ScopeExcludeDeleted:
+		// Matches documents where the field is null or missing
+		filter[field] = nil
+	case query.ScopeOnlyDeleted:
+		filter[field] = bson.M{"$ne": nil}
+	}
+
+	return filter
+}
+
+// Restore clears the soft-delete mark of a document by its ID
+func (m *Model) Restore(ctx context.Context, id string) error {
+	if !m.softDeleteEnabled() {
+		return errors.WithDetails(errors.ErrValidation, "schema does not use soft delete")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("⚠️ Invalid ObjectID format: %s - %v", id, err)
+		return errors.WithDetails(errors.ErrInvalidObjectID, err.Error())
+	}
+
+	// Run pre-update hooks, so hooks such as tenant scoping also apply to restores
+	hc := m.newHookContext(ctx, "update", "Restore")
+	hc.Filter = m.applySoftDelete(bson.M{"_id": objectID}, query.ScopeOnlyDeleted)
+	hc.Update = bson.M{"$unset": bson.M{m.softDeleteField(): ""}}
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+
+	result, err := m.Collection.UpdateOne(ctx, hc.Filter, hc.Update)
+	if err != nil {
+		log.Printf("⚠️ Failed to restore document with ID %s: %v", id, err)
+		return errors.Wrap(errors.ErrDatabase, "failed to restore document")
+	}
+
+	if result.MatchedCount == 0 {
+		log.Printf("⚠️ Deleted document not found with ID: %s", id)
+		return errors.WrapWithID(errors.ErrNotFound, "deleted document not found", id)
+	}
+
+	return nil
+}
+
+// HardDelete permanently removes a document by its ID, even on soft-delete schemas
+func (m *Model) HardDelete(ctx context.Context, id string) error {
+	return m.deleteById(ctx, id, "HardDelete", true)
+}
+
+// Restore clears the soft-delete mark of a document by its ID with type safety
+func (m *GenericModel[T]) Restore(ctx context.Context, id string) error {
+	return m.Model.Restore(ctx, id)
+}
+
+// HardDelete permanently removes a document by its ID with type safety
+func (m *GenericModel[T]) HardDelete(ctx context.Context, id string) error {
+	return m.Model.HardDelete(ctx, id)
+}
@@ -0,0 +1,893 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// supportedUpdateOperators lists the update operators accepted by the update methods
+var supportedUpdateOperators = map[string]bool{
+	"$set":         true,
+	"$unset":       true,
+	"$inc":         true,
+	"$push":        true,
+	"$pull":        true,
+	"$addToSet":    true,
+	"$min":         true,
+	"$max":         true,
+	"$mul":         true,
+	"$rename":      true,
+	"$currentDate": true,
+	"$setOnInsert": true,
+}
+
+// prepareUpdate prepares the update document with timestamp handling.
+// A plain map of fields is treated as a $set; a map of update operators or an
+// update builder is used as is.
+func (m *Model) prepareUpdate(update interface{}) (bson.M, error) {
+	if builder, ok := update.(*query.UpdateBuilder); ok {
+		built, err := builder.Build()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build update")
+		}
+		update = built
+	}
+
+	raw, err := toDocumentMap(update)
+	if err != nil {
+		// Support for other types can be added using reflection
+		// but for now let's support only common types
+		return nil, errors.WithDetails(errors.ErrValidation, "update must be a map or bson type")
+	}
+
+	finalUpdate := bson.M{}
+	if isOperatorDocument(raw) {
+		for op, value := range raw {
+			if !strings.HasPrefix(op, "$") {
+				return nil, errors.WithDetails(errors.ErrValidation,
+					fmt.Sprintf("update cannot mix operators and plain field '%s'", op))
+			}
+			if !supportedUpdateOperators[op] {
+				return nil, errors.WithDetails(errors.ErrValidation,
+					fmt.Sprintf("unsupported update operator '%s'", op))
+			}
+
+			fields, err := toDocumentMap(value)
+			if err != nil {
+				return nil, errors.WithDetails(errors.ErrValidation,
+					fmt.Sprintf("value of update operator '%s' must be a document", op))
+			}
+			finalUpdate[op] = fields
+		}
+	} else {
+		finalUpdate["$set"] = raw
+	}
+
+	// Remove createdAt field (should not be modified)
+	for _, fields := range finalUpdate {
+		delete(fields.(bson.M), "createdAt")
+		delete(fields.(bson.M), "CreatedAt")
+	}
+
+	// The version key is only changed by the model, which increments it on every update
+	if m.versionEnabled() {
+		for _, fields := range finalUpdate {
+			delete(fields.(bson.M), m.Schema.VersionKey)
+		}
+		finalUpdate["$inc"] = incrementVersion(finalUpdate["$inc"], m.Schema.VersionKey)
+	}
+
+	// Add/update updatedAt field if timestamps are enabled, unless an operator already targets it
+	if m.Schema != nil && m.Schema.Timestamps && !updateTargets(finalUpdate, "updatedAt") {
+		set, ok := finalUpdate["$set"].(bson.M)
+		if !ok {
+			set = bson.M{}
+			finalUpdate["$set"] = set
+		}
+		set["updatedAt"] = time.Now()
+	}
+
+	// Drop operators left without fields, MongoDB rejects them
+	for op, fields := range finalUpdate {
+		if len(fields.(bson.M)) == 0 {
+			delete(finalUpdate, op)
+		}
+	}
+
+	if len(finalUpdate) == 0 {
+		return nil, errors.WithDetails(errors.ErrValidation, "update cannot be empty")
+	}
+
+	return finalUpdate, nil
+}
+
+// simulateUpdate loads the document matched by the hook context's filter, applies the
+// update to it and validates the result, running the pre-update and pre-validate
+// middlewares. When no document matches and upsert is set, the simulation starts from
+// the filter's equality conditions. It returns the _id and, on versioned schemas, the
+// version of the matched document, nil when an upsert would insert one, together with
+// the simulated document.
+func (m *Model) simulateUpdate(ctx context.Context, hc *schema.HookContext, sort interface{}, upsert bool) (bson.M, interface{}, error) {
+	// Transform the values being set and check their types
+	if err := m.checkUpdateValues(hc.Update); err != nil {
+		return nil, nil, err
+	}
+
+	findOpts := options.FindOne()
+	if sort != nil {
+		findOpts.SetSort(sort)
+	}
+
+	// Find the existing document
+	var existingDoc bson.M
+	err := m.Collection.FindOne(ctx, hc.Filter, findOpts).Decode(&existingDoc)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("⚠️ Failed to retrieve document for %s: %v", hc.Operation, err)
+		return nil, nil, errors.Wrap(errors.ErrDatabase, "failed to retrieve document")
+	}
+
+	inserting := err == mongo.ErrNoDocuments
+	if inserting && !upsert {
+		log.Printf("⚠️ Document not found with filter: %v", hc.Filter)
+		return nil, nil, errors.ErrNotFound
+	}
+
+	// Start from the filter's equality conditions when inserting
+	var matched bson.M
+	if inserting {
+		existingDoc, err = insertBaseDocument(hc.Filter)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		matched = bson.M{"_id": existingDoc["_id"]}
+		if m.versionEnabled() {
+			matched[m.Schema.VersionKey] = existingDoc[m.Schema.VersionKey]
+		}
+	}
+
+	// Apply update operators to the document
+	if err := applyUpdateOperators(existingDoc, hc.Update); err != nil {
+		return nil, nil, err
+	}
+	if setOnInsert, ok := hc.Update["$setOnInsert"]; ok && inserting {
+		if err := applyUpdateOperators(existingDoc, bson.M{"$set": setOnInsert}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Convert the updated document to the model type when one is known
+	var updatedDoc interface{} = existingDoc
+	if m.Schema != nil && m.Schema.ModelType != nil {
+		newInstance, err := m.toModelInstance(existingDoc)
+		if err != nil {
+			log.Printf("⚠️ Failed to convert document to struct for validation: %v", err)
+			return nil, nil, errors.Wrap(errors.ErrDecoding, "failed to convert to struct for validation")
+		}
+		updatedDoc = newInstance
+	}
+
+	// Apply pre-update middlewares with the updated document
+	if err := m.applyMiddlewares("update", updatedDoc); err != nil {
+		return nil, nil, err
+	}
+
+	// Validate the full updated document, as a map when no model type is known
+	if m.Schema != nil {
+		if err := m.runValidateHooks(ctx, hc.Operation, updatedDoc); err != nil {
+			return nil, nil, err
+		}
+
+		if err := m.Schema.ValidateDocument(updatedDoc); err != nil {
+			log.Printf("⚠️ Document validation failed: %v", err)
+			return nil, nil, err
+		}
+	}
+
+	return matched, updatedDoc, nil
+}
+
+// validateUpdates transforms and checks the values the update of the hook context sets and,
+// if a schema is available, applies the update to every document matching its filter and
+// validates the results, as instances of the model type when one is known
+func (m *Model) validateUpdates(ctx context.Context, hc *schema.HookContext) error {
+	// Transform the values being set and check their types
+	if err := m.checkUpdateValues(hc.Update); err != nil {
+		return err
+	}
+
+	if m.Schema == nil {
+		return nil
+	}
+
+	// Find documents that will be affected
+	cursor, err := m.Collection.Find(ctx, hc.Filter)
+	if err != nil {
+		log.Printf("⚠️ Failed to retrieve documents for validation: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "failed to retrieve documents for validation")
+	}
+	defer cursor.Close(ctx)
+
+	// Validate each document
+	for cursor.Next(ctx) {
+		var existingDoc bson.M
+		if err := cursor.Decode(&existingDoc); err != nil {
+			log.Printf("⚠️ Failed to decode document for validation: %v", err)
+			return errors.Wrap(errors.ErrDecoding, "failed to decode document")
+		}
+
+		// Apply update operators to the existing document
+		if err := applyUpdateOperators(existingDoc, hc.Update); err != nil {
+			return err
+		}
+
+		// Convert existingDoc to a new instance of the model type when one is known
+		var newInstance interface{} = existingDoc
+		if m.Schema.ModelType != nil {
+			instance, err := m.toModelInstance(existingDoc)
+			if err != nil {
+				log.Printf("⚠️ Failed to convert to struct for validation: %v", err)
+				return errors.Wrap(errors.ErrDecoding, "failed to convert to struct for validation")
+			}
+			newInstance = instance
+		}
+
+		// Apply pre-validate middlewares
+		if err := m.runValidateHooks(ctx, hc.Operation, newInstance); err != nil {
+			return err
+		}
+
+		// Validate the full document
+		if err := m.Schema.ValidateDocument(newInstance); err != nil {
+			log.Printf("⚠️ Document validation failed: %v", err)
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		log.Printf("⚠️ Error during cursor iteration: %v", err)
+		return errors.Wrap(errors.ErrDatabase, "error during cursor iteration")
+	}
+
+	return nil
+}
+
+// checkUpdateValues applies the string transforms of the schema to the values an update
+// sets and checks their types
+func (m *Model) checkUpdateValues(update bson.M) error {
+	if m.Schema == nil {
+		return nil
+	}
+	m.Schema.TransformUpdate(update)
+	if err := m.Schema.ValidateUpdate(update); err != nil {
+		log.Printf("⚠️ Update validation failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// incrementVersion adds the version key to an $inc operator
+func incrementVersion(inc interface{}, versionKey string) bson.M {
+	fields, ok := inc.(bson.M)
+	if !ok {
+		fields = bson.M{}
+	}
+	fields[versionKey] = 1
+	return fields
+}
+
+// isOperatorDocument reports whether an update document uses update operators
+func isOperatorDocument(update bson.M) bool {
+	for key := range update {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTargets reports whether any operator of an update document modifies the field
+func updateTargets(update bson.M, field string) bool {
+	for op, value := range update {
+		fields, ok := value.(bson.M)
+		if !ok {
+			continue
+		}
+		if _, exists := fields[field]; exists {
+			return true
+		}
+		if op == "$rename" {
+			for _, target := range fields {
+				if target == field {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// toDocumentMap copies a map or bson document into a new bson.M
+func toDocumentMap(doc interface{}) (bson.M, error) {
+	result := bson.M{}
+	switch d := doc.(type) {
+	case bson.M:
+		for k, v := range d {
+			result[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range d {
+			result[k] = v
+		}
+	case bson.D:
+		for _, e := range d {
+			result[e.Key] = e.Value
+		}
+	default:
+		return nil, fmt.Errorf("unsupported document type %T", doc)
+	}
+	return result, nil
+}
+
+// pathModifier computes the new value of a field from its current value.
+// Returning keep=false removes the field.
+type pathModifier func(current interface{}, exists bool) (value interface{}, keep bool, err error)
+
+// applyUpdateOperators simulates an update document against an existing document,
+// so the result can be validated before the update is written. $setOnInsert is
+// skipped, as it only applies when an upsert inserts a document.
+func applyUpdateOperators(doc bson.M, update bson.M) error {
+	for op, value := range update {
+		if op == "$setOnInsert" {
+			continue
+		}
+
+		fields, err := toDocumentMap(value)
+		if err != nil {
+			return errors.WithDetails(errors.ErrValidation,
+				fmt.Sprintf("value of update operator '%s' must be a document", op))
+		}
+
+		for path, arg := range fields {
+			if err := applyUpdateOperator(doc, op, path, arg); err != nil {
+				return errors.WithDetails(errors.ErrValidation,
+					fmt.Sprintf("cannot apply %s to field '%s': %v", op, path, err))
+			}
+		}
+	}
+	return nil
+}
+
+// applyUpdateOperator applies a single operator to a single field of a document
+func applyUpdateOperator(doc bson.M, op string, path string, arg interface{}) error {
+	switch op {
+	case "$set":
+		return setPath(doc, path, arg)
+
+	case "$unset":
+		return unsetPath(doc, path)
+
+	case "$inc":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				current = int32(0)
+			}
+			result, err := combineNumbers(current, arg, false)
+			return result, true, err
+		})
+
+	case "$mul":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				current = int32(0)
+			}
+			result, err := combineNumbers(current, arg, true)
+			return result, true, err
+		})
+
+	case "$min", "$max":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				return arg, true, nil
+			}
+			cmp, ok := compareValues(arg, current)
+			if !ok {
+				return current, true, nil
+			}
+			if (op == "$min" && cmp < 0) || (op == "$max" && cmp > 0) {
+				return arg, true, nil
+			}
+			return current, true, nil
+		})
+
+	case "$push":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			items, err := toArray(current)
+			if err != nil {
+				return nil, false, err
+			}
+			values, modifiers := eachValues(arg)
+			// MongoDB inserts at $position, then sorts, then slices
+			items = insertArray(items, values, modifiers["$position"])
+			if order, ok := modifiers["$sort"]; ok {
+				sortArray(items, order)
+			}
+			if slice, ok := modifiers["$slice"]; ok {
+				items = sliceArray(items, slice)
+			}
+			return bson.A(items), true, nil
+		})
+
+	case "$addToSet":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			items, err := toArray(current)
+			if err != nil {
+				return nil, false, err
+			}
+			values, _ := eachValues(arg)
+			for _, value := range values {
+				if !containsValue(items, value) {
+					items = append(items, value)
+				}
+			}
+			return bson.A(items), true, nil
+		})
+
+	case "$pull":
+		return modifyPath(doc, path, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				return current, exists, nil
+			}
+			items, err := toArray(current)
+			if err != nil {
+				return nil, false, err
+			}
+			// Condition documents such as {"$gte": 5} are evaluated by the server only
+			if condition, err := toDocumentMap(arg); err == nil && isOperatorDocument(condition) {
+				return bson.A(items), true, nil
+			}
+			kept := bson.A{}
+			for _, item := range items {
+				if !valuesEqual(item, arg) {
+					kept = append(kept, item)
+				}
+			}
+			return kept, true, nil
+		})
+
+	case "$rename":
+		target, ok := arg.(string)
+		if !ok || target == "" {
+			return fmt.Errorf("target field name must be a non-empty string")
+		}
+		value, exists := getPath(doc, path)
+		if !exists {
+			return nil
+		}
+		if err := unsetPath(doc, path); err != nil {
+			return err
+		}
+		return setPath(doc, target, value)
+
+	case "$currentDate":
+		var now interface{} = time.Now()
+		if spec, err := toDocumentMap(arg); err == nil && spec["$type"] == "timestamp" {
+			now = primitive.Timestamp{T: uint32(time.Now().Unix())}
+		}
+		return setPath(doc, path, now)
+
+	default:
+		return fmt.Errorf("unsupported update operator")
+	}
+}
+
+// getPath returns the value at a dotted path of a document
+func getPath(doc interface{}, path string) (interface{}, bool) {
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case bson.M:
+			value, ok := c[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case map[string]interface{}:
+			value, ok := c[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case bson.D:
+			found := false
+			for _, e := range c {
+				if e.Key == part {
+					current = e.Value
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case bson.A, []interface{}:
+			items, _ := toArray(c)
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(items) {
+				return nil, false
+			}
+			current = items[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath sets the value at a dotted path of a document
+func setPath(doc bson.M, path string, value interface{}) error {
+	return modifyPath(doc, path, func(interface{}, bool) (interface{}, bool, error) {
+		return value, true, nil
+	})
+}
+
+// unsetPath removes the value at a dotted path of a document
+func unsetPath(doc bson.M, path string) error {
+	if _, exists := getPath(doc, path); !exists {
+		return nil
+	}
+	return modifyPath(doc, path, func(interface{}, bool) (interface{}, bool, error) {
+		return nil, false, nil
+	})
+}
+
+// modifyPath applies a modifier to the value at a dotted path of a document,
+// creating intermediate documents as needed
+func modifyPath(doc bson.M, path string, modify pathModifier) error {
+	_, err := modifyIn(doc, strings.Split(path, "."), modify)
+	return err
+}
+
+// modifyIn applies a modifier within a container and returns the updated container
+func modifyIn(container interface{}, parts []string, modify pathModifier) (interface{}, error) {
+	key := parts[0]
+
+	switch c := container.(type) {
+	case nil:
+		return modifyIn(bson.M{}, parts, modify)
+	case map[string]interface{}:
+		return modifyIn(bson.M(c), parts, modify)
+	case bson.D:
+		converted, _ := toDocumentMap(c)
+		return modifyIn(converted, parts, modify)
+	case bson.M:
+		current, exists := c[key]
+		if len(parts) > 1 {
+			// A positional operator on a missing array is rejected by the server
+			if !exists && isPositional(parts[1]) {
+				return c, nil
+			}
+			child, err := modifyIn(current, parts[1:], modify)
+			if err != nil {
+				return nil, err
+			}
+			c[key] = child
+			return c, nil
+		}
+		value, keep, err := modify(current, exists)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			c[key] = value
+		} else {
+			delete(c, key)
+		}
+		return c, nil
+	case bson.A, []interface{}:
+		items, _ := toArray(c)
+		switch {
+		case key == "$[]":
+			// $[] updates every element
+			for i := range items {
+				if err := modifyElement(items, i, parts, modify); err != nil {
+					return nil, err
+				}
+			}
+			return bson.A(items), nil
+		case isPositional(key):
+			// The elements matched by the query or by arrayFilters are only known to the
+			// server; ValidateUpdate checks the values set in them instead
+			return bson.A(items), nil
+		}
+
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("cannot use '%s' as an array index", key)
+		}
+		// MongoDB pads arrays with nulls when setting past their end
+		for len(items) <= index {
+			items = append(items, nil)
+		}
+		if err := modifyElement(items, index, parts, modify); err != nil {
+			return nil, err
+		}
+		return bson.A(items), nil
+	default:
+		return nil, fmt.Errorf("cannot create field '%s' in a non-document value", key)
+	}
+}
+
+// modifyElement applies a modifier within the element of an array at an index, or to
+// the element itself when parts addresses it
+func modifyElement(items []interface{}, index int, parts []string, modify pathModifier) error {
+	if len(parts) > 1 {
+		child, err := modifyIn(items[index], parts[1:], modify)
+		if err != nil {
+			return err
+		}
+		items[index] = child
+		return nil
+	}
+	value, keep, err := modify(items[index], true)
+	if err != nil {
+		return err
+	}
+	if keep {
+		items[index] = value
+	} else {
+		// $unset on an array element sets it to null
+		items[index] = nil
+	}
+	return nil
+}
+
+// isPositional reports whether a path segment is a positional operator: $, $[] or $[identifier]
+func isPositional(segment string) bool {
+	return segment == "$" || strings.HasPrefix(segment, "$[")
+}
+
+// toArray converts an array value into a []interface{}, treating nil as an empty array
+func toArray(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case bson.A:
+		return []interface{}(v), nil
+	case []interface{}:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("value is not an array")
+	}
+	items := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// eachValues returns the values added by $push or $addToSet, expanding $each,
+// together with any other modifiers of the argument
+func eachValues(arg interface{}) ([]interface{}, bson.M) {
+	spec, err := toDocumentMap(arg)
+	if err != nil {
+		return []interface{}{arg}, nil
+	}
+	each, ok := spec["$each"]
+	if !ok {
+		return []interface{}{arg}, nil
+	}
+	values, err := toArray(each)
+	if err != nil {
+		return []interface{}{each}, spec
+	}
+	return values, spec
+}
+
+// insertArray inserts values into an array at a $position modifier, from the end of the
+// array when negative, or appends them when there is none
+func insertArray(items []interface{}, values []interface{}, position interface{}) []interface{} {
+	n, _, ok := toNumber(position)
+	if !ok {
+		return append(items, values...)
+	}
+	index := int(n)
+	if index < 0 {
+		index += len(items)
+	}
+	index = max(0, min(index, len(items)))
+
+	result := make([]interface{}, 0, len(items)+len(values))
+	result = append(result, items[:index]...)
+	result = append(result, values...)
+	return append(result, items[index:]...)
+}
+
+// sortArray applies a $sort modifier to an array: 1 or -1 sorts the elements, a document
+// like {"score": -1} sorts sub-documents by their fields. Values that cannot be compared
+// keep their order.
+func sortArray(items []interface{}, order interface{}) {
+	var keys bson.D
+	if direction, _, ok := toNumber(order); ok {
+		keys = bson.D{{Key: "", Value: direction}}
+	} else if spec, ok := order.(bson.D); ok {
+		keys = spec
+	} else if spec, err := toDocumentMap(order); err == nil {
+		for field, direction := range spec {
+			keys = append(keys, bson.E{Key: field, Value: direction})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := items[i], items[j]
+			if key.Key != "" {
+				a, _ = getPath(a, key.Key)
+				b, _ = getPath(b, key.Key)
+			}
+			cmp, ok := compareValues(a, b)
+			if !ok || cmp == 0 {
+				continue
+			}
+			if direction, _, _ := toNumber(key.Value); direction < 0 {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// sliceArray applies a $slice modifier to an array
+func sliceArray(items []interface{}, slice interface{}) []interface{} {
+	n, _, ok := toNumber(slice)
+	if !ok {
+		return items
+	}
+	limit := int(n)
+	switch {
+	case limit >= 0 && limit < len(items):
+		return items[:limit]
+	case limit < 0 && -limit < len(items):
+		return items[len(items)+limit:]
+	default:
+		return items
+	}
+}
+
+// containsValue reports whether an array contains a value
+func containsValue(items []interface{}, value interface{}) bool {
+	for _, item := range items {
+		if valuesEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares two values, treating numbers of different types as equal when their values are
+func valuesEqual(a, b interface{}) bool {
+	if af, _, ok := toNumber(a); ok {
+		if bf, _, ok := toNumber(b); ok {
+			return af == bf
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toNumber converts a numeric value to float64 and reports whether it is an integer type
+func toNumber(value interface{}) (float64, bool, bool) {
+	if value == nil {
+		return 0, false, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true, true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), false, true
+	default:
+		return 0, false, false
+	}
+}
+
+// combineNumbers adds or multiplies two numbers, keeping integer results as int64
+func combineNumbers(current, arg interface{}, multiply bool) (interface{}, error) {
+	a, aIsInt, ok := toNumber(current)
+	if !ok {
+		return nil, fmt.Errorf("existing value is not a number")
+	}
+	b, bIsInt, ok := toNumber(arg)
+	if !ok {
+		return nil, fmt.Errorf("argument is not a number")
+	}
+
+	if aIsInt && bIsInt {
+		ai := reflect.ValueOf(current)
+		bi := reflect.ValueOf(arg)
+		x, y := integerValue(ai), integerValue(bi)
+		if multiply {
+			return x * y, nil
+		}
+		return x + y, nil
+	}
+
+	if multiply {
+		return a * b, nil
+	}
+	return a + b, nil
+}
+
+// integerValue returns the value of an integer reflect.Value as int64
+func integerValue(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return v.Int()
+	}
+}
+
+// compareValues compares numbers, strings and dates. The second result is false
+// when the values cannot be compared.
+func compareValues(a, b interface{}) (int, bool) {
+	if af, _, ok := toNumber(a); ok {
+		if bf, _, ok := toNumber(b); ok {
+			switch {
+			case af < bf:
+				return -1, true
+			case af > bf:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+		return 0, false
+	}
+
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(as, bs), true
+		}
+		return 0, false
+	}
+
+	at, aok := toTime(a)
+	bt, bok := toTime(b)
+	if aok && bok {
+		return at.Compare(bt), true
+	}
+
+	return 0, false
+}
+
+// toTime converts time.Time and primitive.DateTime values to time.Time
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case primitive.DateTime:
+		return v.Time(), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Upsert updates the document matching the filter, or inserts a new one built from the
+// filter and the update when none matches. Schema defaults and createdAt are only set
+// on insert, updatedAt is always set. The update only applies to the document it was
+// validated against: on versioned schemas it fails with ErrVersionConflict if that
+// document changed meanwhile, and inserted documents start at version 0 like with
+// Create. The resulting document is decoded into result, and the returned flag reports
+// whether it was inserted.
+func (m *Model) Upsert(ctx context.Context, filter interface{}, update interface{}, result interface{}) (bool, error) {
+	if m.Collection == nil {
+		return false, errors.ErrNilCollection
+	}
+
+	filterMap, err := toFilterMap(filter)
+	if err != nil {
+		return false, err
+	}
+
+	// 1. Prepare update data (handle timestamps and insert-only fields)
+	finalUpdate, err := m.prepareUpdate(update)
+	if err != nil {
+		return false, err
+	}
+	m.addInsertOnlyFields(finalUpdate, filterMap)
+
+	// 2. Run pre-update hooks, which may scope the filter or change the update
+	hc := m.newHookContext(ctx, "update", "Upsert")
+	hc.Filter = m.applySoftDelete(filterMap, query.ScopeExcludeDeleted)
+	hc.Update = finalUpdate
+	if err := m.runPreHooks(hc); err != nil {
+		return false, err
+	}
+
+	// 3. Validate the document as it will look after the update
+	matched, _, err := m.simulateUpdate(ctx, hc, nil, true)
+	if err != nil {
+		return false, err
+	}
+
+	// 4. Update the document that was validated, at the validated version, or insert
+	// the document when none matched
+	writeFilter, writeUpdate, upsert := m.upsertWrite(hc.Filter, hc.Update, matched, true)
+	updateResult, err := m.Collection.UpdateOne(ctx, writeFilter, writeUpdate, options.Update().SetUpsert(upsert))
+	if err != nil {
+		log.Printf("⚠️ Failed to upsert document: %v", err)
+		return false, errors.Wrap(errors.ErrDatabase, "failed to upsert document")
+	}
+	if matched != nil && updateResult.MatchedCount == 0 {
+		return false, m.matchFailure(ctx, hc.Filter, formatID(matched["_id"]))
+	}
+
+	// 5. Read back the written document
+	inserted := updateResult.UpsertedID != nil
+	readFilter := hc.Filter
+	if inserted {
+		readFilter = bson.M{"_id": updateResult.UpsertedID}
+	} else if matched != nil {
+		readFilter = bson.M{"_id": matched["_id"]}
+	}
+
+	if err := m.Collection.FindOne(ctx, readFilter).Decode(result); err != nil {
+		log.Printf("⚠️ Failed to retrieve upserted document: %v", err)
+		return inserted, errors.Wrap(errors.ErrDatabase, "failed to retrieve upserted document")
+	}
+
+	// 6. Apply post-update middlewares with the written document
+	return inserted, m.runPostHooks(hc, result)
+}
+
+// Upsert updates or inserts a document with type safety, returning the
+// resulting document and whether it was inserted
+func (m *GenericModel[T]) Upsert(ctx context.Context, filter interface{}, update interface{}) (*T, bool, error) {
+	result := new(T)
+	inserted, err := m.Model.Upsert(ctx, filter, update, result)
+	if err != nil {
+		return nil, inserted, err
+	}
+	return result, inserted, nil
+}
+
+// addInsertOnlyFields adds schema defaults and createdAt to the $setOnInsert operator
+// of an update. Fields set by the filter or by another operator are left out, as
+// MongoDB rejects conflicting updates of the same field.
+func (m *Model) addInsertOnlyFields(update bson.M, filter bson.M) {
+	if m.Schema == nil {
+		return
+	}
+
+	setOnInsert, ok := update["$setOnInsert"].(bson.M)
+	if !ok {
+		setOnInsert = bson.M{}
+	}
+
+	add := func(field string, value interface{}) {
+		if _, exists := setOnInsert[field]; exists {
+			return
+		}
+		if _, exists := filter[field]; exists {
+			return
+		}
+		if updateTargets(update, field) {
+			return
+		}
+		setOnInsert[field] = value
+	}
+
+	for fieldName, field := range m.Schema.Fields {
+		if value, ok := field.DefaultValue(); ok {
+			add(fieldName, value)
+		}
+	}
+
+	if m.Schema.Timestamps {
+		add("createdAt", time.Now())
+	}
+
+	if len(setOnInsert) > 0 {
+		update["$setOnInsert"] = setOnInsert
+	}
+}
+
+// insertBaseDocument builds the document an upsert starts from when inserting:
+// the equality conditions of the filter
+func insertBaseDocument(filter bson.M) (bson.M, error) {
+	doc := bson.M{}
+	for key, value := range filter {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		if condition, err := toDocumentMap(value); err == nil && isOperatorDocument(condition) {
+			if eq, ok := condition["$eq"]; ok {
+				value = eq
+			} else {
+				continue
+			}
+		}
+		if err := setPath(doc, key, value); err != nil {
+			return nil, errors.WithDetails(errors.ErrValidation, err.Error())
+		}
+	}
+	return doc, nil
+}
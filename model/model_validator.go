@@ -0,0 +1,86 @@
+package model
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Validation levels and actions of ApplyCollectionValidator
+const (
+	// ValidationLevelStrict validates every insert and update
+	ValidationLevelStrict = "strict"
+	// ValidationLevelModerate skips updates of existing documents that don't pass validation
+	ValidationLevelModerate = "moderate"
+	// ValidationLevelOff disables validation
+	ValidationLevelOff = "off"
+	// ValidationActionError rejects writes that fail validation
+	ValidationActionError = "error"
+	// ValidationActionWarn logs writes that fail validation on the server and accepts them
+	ValidationActionWarn = "warn"
+)
+
+// namespaceExistsCode is the server error code of creating an existing collection
+const namespaceExistsCode = 48
+
+// ApplyCollectionValidator makes the server validate the documents of the collection
+// with the $jsonSchema of the model's schema, see schema.ToJSONSchema. The collection
+// is created with the validator, or modified with collMod when it exists. An empty
+// level or action keeps the server default, ValidationLevelStrict and ValidationActionError.
+func (m *Model) ApplyCollectionValidator(ctx context.Context, level, action string) error {
+	if m.Collection == nil || m.DB == nil {
+		return errors.ErrNilCollection
+	}
+	if m.Schema == nil {
+		return errors.WithDetails(errors.ErrValidation, "model has no schema to build a validator from")
+	}
+
+	name := m.Collection.Name()
+	validator := bson.M{"$jsonSchema": m.Schema.ToJSONSchema()}
+
+	names, err := m.DB.ListCollectionNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return errors.Wrap(errors.ErrDatabase, err.Error())
+	}
+
+	if len(names) == 0 {
+		createOptions := options.CreateCollection().SetValidator(validator)
+		if level != "" {
+			createOptions.SetValidationLevel(level)
+		}
+		if action != "" {
+			createOptions.SetValidationAction(action)
+		}
+
+		err := m.DB.CreateCollection(ctx, name, createOptions)
+		if err == nil {
+			log.Printf("✅ Created collection '%s' with a schema validator", name)
+			return nil
+		}
+
+		// The collection may have been created concurrently; modify it instead
+		var cmdErr mongo.CommandError
+		if !stderrors.As(err, &cmdErr) || cmdErr.Code != namespaceExistsCode {
+			return errors.Wrap(errors.ErrDatabase, err.Error())
+		}
+	}
+
+	command := bson.D{{Key: "collMod", Value: name}, {Key: "validator", Value: validator}}
+	if level != "" {
+		command = append(command, bson.E{Key: "validationLevel", Value: level})
+	}
+	if action != "" {
+		command = append(command, bson.E{Key: "validationAction", Value: action})
+	}
+	if err := m.DB.RunCommand(ctx, command).Err(); err != nil {
+		return errors.Wrap(errors.ErrDatabase, err.Error())
+	}
+
+	log.Printf("✅ Applied schema validator to collection '%s'", name)
+	return nil
+}
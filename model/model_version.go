@@ -0,0 +1,287 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// versionEnabled reports whether the model's schema uses a version key
+func (m *Model) versionEnabled() bool {
+	return m.Schema != nil && m.Schema.VersionKey != ""
+}
+
+// versionField returns the struct field of a document mapped to the version key,
+// or an invalid value when the document has none
+func (m *Model) versionField(doc interface{}) reflect.Value {
+	if !m.versionEnabled() {
+		return reflect.Value{}
+	}
+
+	val := reflect.ValueOf(doc)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("bson"), ",")[0]
+		if name == m.Schema.VersionKey {
+			return val.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// setVersion sets a numeric version field
+func setVersion(field reflect.Value, version int64) {
+	if !field.CanSet() {
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(version)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(version))
+	case reflect.Float32, reflect.Float64:
+		field.SetFloat(float64(version))
+	}
+}
+
+// withInitialVersion returns the document to insert with its version set to 0. Documents
+// without a version field are converted to a bson.M holding the version key.
+func (m *Model) withInitialVersion(doc interface{}) (interface{}, error) {
+	if !m.versionEnabled() {
+		return doc, nil
+	}
+
+	if field := m.versionField(doc); field.IsValid() && field.CanSet() {
+		setVersion(field, 0)
+		return doc, nil
+	}
+
+	docMap, err := toFilterMap(doc)
+	if err != nil {
+		return nil, errors.WithDetails(errors.ErrValidation, "document must be a struct or a map")
+	}
+	docMap[m.Schema.VersionKey] = 0
+	return docMap, nil
+}
+
+// versionCondition returns the filter condition matching a stored version. Version 0
+// also matches documents written before the version key was enabled.
+func versionCondition(version interface{}) interface{} {
+	if n, _, ok := toNumber(version); ok && n == 0 {
+		return bson.M{"$in": bson.A{version, nil}}
+	}
+	return version
+}
+
+// versionMatches reports whether a version supplied by the caller is the stored one,
+// like versionCondition matches it
+func versionMatches(supplied, stored interface{}) bool {
+	s, _, ok := toNumber(supplied)
+	if !ok {
+		return false
+	}
+	if stored == nil {
+		return s == 0
+	}
+	v, _, ok := toNumber(stored)
+	return ok && s == v
+}
+
+// nextVersion returns the version following a stored one, treating a missing version as 0
+func nextVersion(stored interface{}) int64 {
+	version, _, _ := toNumber(stored)
+	return int64(version) + 1
+}
+
+// pinFilter restricts a filter to the document that was validated, at the version it was
+// validated at on versioned schemas, so a concurrent change makes the write match nothing.
+// A nil matched document, of an upsert that inserts, leaves the filter as is.
+func (m *Model) pinFilter(filter bson.M, matched bson.M) bson.M {
+	if matched == nil {
+		return filter
+	}
+	pinned := cloneFilter(filter)
+	pinned["_id"] = matched["_id"]
+	if m.versionEnabled() {
+		pinned[m.Schema.VersionKey] = versionCondition(matched[m.Schema.VersionKey])
+	}
+	return pinned
+}
+
+// upsertWrite returns the filter, update and upsert flag of a validated write. A write of
+// a matched document is pinned to it and never inserts, so a concurrent change can't turn
+// it into an insert; an insert starts the document at version 0, like Create.
+func (m *Model) upsertWrite(filter, update, matched bson.M, upsert bool) (bson.M, bson.M, bool) {
+	if matched != nil {
+		return m.pinFilter(filter, matched), update, false
+	}
+	if !upsert || !m.versionEnabled() {
+		return filter, update, upsert
+	}
+
+	// MongoDB rejects an update setting the version in both $inc and $setOnInsert
+	key := m.Schema.VersionKey
+	insert := bson.M{}
+	for op, fields := range update {
+		insert[op] = fields
+	}
+	if inc, ok := update["$inc"].(bson.M); ok {
+		rest := bson.M{}
+		for field, value := range inc {
+			if field != key {
+				rest[field] = value
+			}
+		}
+		delete(insert, "$inc")
+		if len(rest) > 0 {
+			insert["$inc"] = rest
+		}
+	}
+
+	setOnInsert := bson.M{}
+	if existing, ok := update["$setOnInsert"].(bson.M); ok {
+		for field, value := range existing {
+			setOnInsert[field] = value
+		}
+	}
+	setOnInsert[key] = 0
+	insert["$setOnInsert"] = setOnInsert
+	return filter, insert, true
+}
+
+// formatID formats a document ID for errors and logs
+func formatID(id interface{}) string {
+	if objectID, ok := id.(primitive.ObjectID); ok {
+		return objectID.Hex()
+	}
+	return fmt.Sprint(id)
+}
+
+// matchFailure explains why a versioned write matched no document: either the
+// document no longer exists, or it was changed by another writer
+func (m *Model) matchFailure(ctx context.Context, filter bson.M, id string) error {
+	if m.versionEnabled() {
+		count, err := m.Collection.CountDocuments(ctx, filter)
+		if err == nil && count > 0 {
+			log.Printf("⚠️ Version conflict for document with ID: %s", id)
+			return errors.WrapWithID(errors.ErrVersionConflict, "document was modified", id)
+		}
+	}
+
+	log.Printf("⚠️ Document not found with ID: %s", id)
+	return errors.WrapWithID(errors.ErrNotFound, "document not found", id)
+}
+
+// Save writes a document back to its collection. Documents without an ID are created;
+// otherwise the stored document is updated with the fields of the given one. The
+// document is validated before the update is built, and pre-update hooks receive the
+// update, which they may change. On versioned schemas the write only succeeds while the
+// stored version still matches the document's, and the document's version is incremented.
+func (m *Model) Save(ctx context.Context, doc interface{}) error {
+	if m.Collection == nil {
+		return errors.ErrNilCollection
+	}
+
+	val := reflect.ValueOf(doc)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return errors.WithDetails(errors.ErrValidation, "document must be a pointer to a struct")
+	}
+
+	idField := val.Elem().FieldByName("ID")
+	if !idField.IsValid() || idField.IsZero() {
+		return m.Create(ctx, doc)
+	}
+	id := idField.Interface()
+	idString := formatID(id)
+
+	// Apply pre-update middlewares and validate the document
+	if err := m.applyMiddlewares("update", doc); err != nil {
+		return err
+	}
+
+	m.Schema.ApplyTransforms(doc)
+
+	if err := m.runValidateHooks(ctx, "Save", doc); err != nil {
+		return err
+	}
+
+	if err := m.Schema.ValidateDocument(doc); err != nil {
+		log.Printf("⚠️ Document validation failed: %v", err)
+		return err
+	}
+
+	m.addTimestamps(doc, false)
+
+	// Build the update from the document's fields
+	fields, err := toFilterMap(doc)
+	if err != nil {
+		return err
+	}
+	delete(fields, "_id")
+	delete(fields, "createdAt")
+
+	hc := m.newHookContext(ctx, "update", "Save")
+	hc.Filter = m.applySoftDelete(bson.M{"_id": id}, query.ScopeExcludeDeleted)
+	hc.Document = doc
+	hc.Update = bson.M{"$set": fields}
+	if m.versionEnabled() {
+		delete(fields, m.Schema.VersionKey)
+		hc.Update["$inc"] = bson.M{m.Schema.VersionKey: 1}
+	}
+
+	// Run pre-update hooks, which may scope the filter or change the update, and check
+	// the values of the update they return
+	if err := m.runPreHooks(hc); err != nil {
+		return err
+	}
+	if err := m.checkUpdateValues(hc.Update); err != nil {
+		return err
+	}
+
+	// Only write if the stored version still matches the document's
+	filter := hc.Filter
+	versionField := m.versionField(doc)
+	if m.versionEnabled() && versionField.IsValid() {
+		filter = cloneFilter(hc.Filter)
+		filter[m.Schema.VersionKey] = versionCondition(versionField.Interface())
+	}
+
+	result, err := m.Collection.UpdateOne(ctx, filter, hc.Update)
+	if err != nil {
+		log.Printf("⚠️ Failed to save document with ID %s: %v", idString, err)
+		return errors.Wrap(errors.ErrDatabase, "failed to save document")
+	}
+
+	if result.MatchedCount == 0 {
+		return m.matchFailure(ctx, hc.Filter, idString)
+	}
+
+	if versionField.IsValid() {
+		if version, _, ok := toNumber(versionField.Interface()); ok {
+			setVersion(versionField, int64(version)+1)
+		}
+	}
+
+	// Apply post-update middlewares with the saved document
+	return m.runPostHooks(hc, doc)
+}
+
+// Save writes a document back to its collection with type safety
+func (m *GenericModel[T]) Save(ctx context.Context, doc *T) error {
+	return m.Model.Save(ctx, doc)
+}
@@ -0,0 +1,340 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pipeline helps to build MongoDB aggregation pipelines
+type Pipeline struct {
+	stages       mongo.Pipeline
+	deletedScope DeletedScope
+	err          error
+}
+
+// NewPipeline creates a new aggregation pipeline builder
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		stages: mongo.Pipeline{},
+	}
+}
+
+// PipelineWithError creates a new pipeline builder that starts with an error
+// This is useful for chaining error handling
+func PipelineWithError(err error) *Pipeline {
+	pipeline := NewPipeline()
+	pipeline.err = err
+	return pipeline
+}
+
+// Error returns any error that occurred during pipeline building
+func (p *Pipeline) Error() error {
+	return p.err
+}
+
+// addStage appends a stage to the pipeline
+func (p *Pipeline) addStage(operator string, value interface{}) *Pipeline {
+	p.stages = append(p.stages, bson.D{{Key: operator, Value: value}})
+	return p
+}
+
+// Match filters the documents with the filter of a query builder. A WithDeleted
+// or OnlyDeleted scope of the query applies to the whole pipeline.
+func (p *Pipeline) Match(queryBuilder *Builder) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if queryBuilder == nil {
+		p.err = errors.WithDetails(errors.ErrValidation, "match query cannot be nil")
+		return p
+	}
+
+	filter, err := queryBuilder.GetFilter()
+	if err != nil {
+		p.err = err
+		return p
+	}
+
+	if queryBuilder.DeletedScope() != ScopeExcludeDeleted {
+		p.deletedScope = queryBuilder.DeletedScope()
+	}
+
+	return p.addStage("$match", filter)
+}
+
+// Group groups the documents by an _id expression and computes the accumulated fields,
+// e.g. Group("$role", bson.M{"count": bson.M{"$sum": 1}})
+func (p *Pipeline) Group(id interface{}, accumulators bson.M) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	group := bson.M{"_id": id}
+	for field, accumulator := range accumulators {
+		if field == "" || field == "_id" {
+			p.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("invalid group field '%s'", field))
+			return p
+		}
+		group[field] = accumulator
+	}
+
+	return p.addStage("$group", group)
+}
+
+// Project includes, excludes or computes fields
+func (p *Pipeline) Project(fields bson.M) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if len(fields) == 0 {
+		p.err = errors.WithDetails(errors.ErrValidation, "projection cannot be empty")
+		return p
+	}
+
+	return p.addStage("$project", fields)
+}
+
+// Sort adds sort criteria. Consecutive calls add keys to the same sort stage.
+func (p *Pipeline) Sort(key string, ascending bool) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if key == "" {
+		p.err = errors.WithDetails(errors.ErrValidation, "sort key cannot be empty")
+		return p
+	}
+
+	value := -1
+	if ascending {
+		value = 1
+	}
+
+	// Extend the previous stage if it is a sort
+	if last := len(p.stages) - 1; last >= 0 && p.stages[last][0].Key == "$sort" {
+		sort := append(bson.D{}, p.stages[last][0].Value.(bson.D)...)
+		updated := false
+		for i, e := range sort {
+			if e.Key == key {
+				sort[i].Value = value
+				updated = true
+			}
+		}
+		if !updated {
+			sort = append(sort, bson.E{Key: key, Value: value})
+		}
+		p.stages[last] = bson.D{{Key: "$sort", Value: sort}}
+		return p
+	}
+
+	return p.addStage("$sort", bson.D{{Key: key, Value: value}})
+}
+
+// Limit sets the maximum number of documents passed to the next stage
+func (p *Pipeline) Limit(limit int64) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if limit <= 0 {
+		p.err = errors.WithDetails(errors.ErrValidation, "limit must be positive")
+		return p
+	}
+
+	return p.addStage("$limit", limit)
+}
+
+// Skip sets the number of documents to skip
+func (p *Pipeline) Skip(skip int64) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if skip < 0 {
+		p.err = errors.WithDetails(errors.ErrValidation, "skip cannot be negative")
+		return p
+	}
+
+	return p.addStage("$skip", skip)
+}
+
+// Unwind outputs a document for each element of an array field. Documents with a
+// missing or empty array are kept when preserveEmpty is true.
+func (p *Pipeline) Unwind(path string, preserveEmpty ...bool) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	path = strings.TrimPrefix(path, "$")
+	if path == "" {
+		p.err = errors.WithDetails(errors.ErrValidation, "unwind path cannot be empty")
+		return p
+	}
+
+	if len(preserveEmpty) > 0 && preserveEmpty[0] {
+		return p.addStage("$unwind", bson.M{
+			"path":                       "$" + path,
+			"preserveNullAndEmptyArrays": true,
+		})
+	}
+	return p.addStage("$unwind", "$"+path)
+}
+
+// Lookup joins the documents of another collection whose foreignField matches
+// localField, storing them as an array in the as field
+func (p *Pipeline) Lookup(from, localField, foreignField, as string) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if from == "" || localField == "" || foreignField == "" || as == "" {
+		p.err = errors.WithDetails(errors.ErrValidation, "lookup requires from, localField, foreignField and as")
+		return p
+	}
+
+	return p.addStage("$lookup", bson.M{
+		"from":         from,
+		"localField":   localField,
+		"foreignField": foreignField,
+		"as":           as,
+	})
+}
+
+// AddFields adds computed fields to the documents
+func (p *Pipeline) AddFields(fields bson.M) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if len(fields) == 0 {
+		p.err = errors.WithDetails(errors.ErrValidation, "fields cannot be empty")
+		return p
+	}
+
+	return p.addStage("$addFields", fields)
+}
+
+// Facet runs several sub-pipelines on the same documents, storing the output
+// of each one in the field of its name
+func (p *Pipeline) Facet(facets map[string]*Pipeline) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if len(facets) == 0 {
+		p.err = errors.WithDetails(errors.ErrValidation, "facets cannot be empty")
+		return p
+	}
+
+	facet := bson.M{}
+	for name, pipeline := range facets {
+		if name == "" || pipeline == nil {
+			p.err = errors.WithDetails(errors.ErrValidation, "facet must have a name and a pipeline")
+			return p
+		}
+
+		stages, err := pipeline.Build()
+		if err != nil {
+			p.err = err
+			return p
+		}
+		facet[name] = stages
+	}
+
+	return p.addStage("$facet", facet)
+}
+
+// Count outputs a single document holding the number of documents in the given field
+func (p *Pipeline) Count(field string) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if field == "" || strings.HasPrefix(field, "$") || strings.Contains(field, ".") {
+		p.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("invalid count field '%s'", field))
+		return p
+	}
+
+	return p.addStage("$count", field)
+}
+
+// ReplaceRoot replaces each document with an embedded document. A string is
+// taken as a field path, other values as an expression.
+func (p *Pipeline) ReplaceRoot(newRoot interface{}) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if path, ok := newRoot.(string); ok {
+		path = strings.TrimPrefix(path, "$")
+		if path == "" {
+			p.err = errors.WithDetails(errors.ErrValidation, "new root cannot be empty")
+			return p
+		}
+		newRoot = "$" + path
+	}
+
+	if newRoot == nil {
+		p.err = errors.WithDetails(errors.ErrValidation, "new root cannot be empty")
+		return p
+	}
+
+	return p.addStage("$replaceRoot", bson.M{"newRoot": newRoot})
+}
+
+// Stage adds a stage the builder has no method for, e.g.
+// Stage("$geoNear", bson.M{"near": point, "distanceField": "distance"})
+func (p *Pipeline) Stage(operator string, value interface{}) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	if !strings.HasPrefix(operator, "$") || len(operator) == 1 {
+		p.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("invalid stage operator '%s'", operator))
+		return p
+	}
+
+	return p.addStage(operator, value)
+}
+
+// WithDeleted makes the pipeline include soft-deleted documents as well
+func (p *Pipeline) WithDeleted() *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	p.deletedScope = ScopeWithDeleted
+	return p
+}
+
+// OnlyDeleted makes the pipeline include only soft-deleted documents
+func (p *Pipeline) OnlyDeleted() *Pipeline {
+	if p.err != nil {
+		return p
+	}
+
+	p.deletedScope = ScopeOnlyDeleted
+	return p
+}
+
+// DeletedScope returns which documents enter the pipeline on soft-delete schemas
+func (p *Pipeline) DeletedScope() DeletedScope {
+	return p.deletedScope
+}
+
+// Build returns the pipeline stages, or an error if one occurred
+func (p *Pipeline) Build() (mongo.Pipeline, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	stages := make(mongo.Pipeline, len(p.stages))
+	copy(stages, p.stages)
+	return stages, nil
+}
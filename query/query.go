@@ -21,13 +21,27 @@ const (
 	OpRegex        = "$regex"
 )
 
+// DeletedScope controls which documents a query matches on soft-delete schemas
+type DeletedScope int
+
+const (
+	// ScopeExcludeDeleted matches only documents that are not soft-deleted (default)
+	ScopeExcludeDeleted DeletedScope = iota
+	// ScopeWithDeleted matches documents regardless of their soft-delete state
+	ScopeWithDeleted
+	// ScopeOnlyDeleted matches only soft-deleted documents
+	ScopeOnlyDeleted
+)
+
 // Builder helps to build MongoDB queries
 type Builder struct {
-	filter bson.M
-	sort   bson.D
-	limit  int64
-	skip   int64
-	err    error
+	filter       bson.M
+	sort         bson.D
+	limit        int64
+	skip         int64
+	deletedScope DeletedScope
+	populate     []string
+	err          error
 }
 
 // New creates a new query builder
@@ -241,6 +255,53 @@ func (b *Builder) Skip(skip int64) *Builder {
 	return b
 }
 
+// WithDeleted makes the query match soft-deleted documents as well
+func (b *Builder) WithDeleted() *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	b.deletedScope = ScopeWithDeleted
+	return b
+}
+
+// OnlyDeleted makes the query match only soft-deleted documents
+func (b *Builder) OnlyDeleted() *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	b.deletedScope = ScopeOnlyDeleted
+	return b
+}
+
+// Populate resolves the referenced documents of the given fields after the query,
+// e.g. Populate("customerId"). The fields must declare a Ref in the schema.
+func (b *Builder) Populate(paths ...string) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			b.err = errors.WithDetails(errors.ErrValidation, "populate path cannot be empty")
+			return b
+		}
+		b.populate = append(b.populate, path)
+	}
+	return b
+}
+
+// PopulatePaths returns the fields whose referenced documents are resolved after the query
+func (b *Builder) PopulatePaths() []string {
+	return b.populate
+}
+
+// DeletedScope returns which documents the query matches on soft-delete schemas
+func (b *Builder) DeletedScope() DeletedScope {
+	return b.deletedScope
+}
+
 // GetFilter returns the filter
 func (b *Builder) GetFilter() (bson.M, error) {
 	if b.err != nil {
@@ -0,0 +1,208 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Operator constants for MongoDB update operators
+const (
+	UpdateOpSet         = "$set"
+	UpdateOpUnset       = "$unset"
+	UpdateOpInc         = "$inc"
+	UpdateOpMul         = "$mul"
+	UpdateOpMin         = "$min"
+	UpdateOpMax         = "$max"
+	UpdateOpPush        = "$push"
+	UpdateOpPull        = "$pull"
+	UpdateOpAddToSet    = "$addToSet"
+	UpdateOpRename      = "$rename"
+	UpdateOpCurrentDate = "$currentDate"
+)
+
+// UpdateBuilder helps to build MongoDB update documents
+type UpdateBuilder struct {
+	update bson.M
+	fields map[string]string
+	err    error
+}
+
+// Update creates a new update builder
+func Update() *UpdateBuilder {
+	return &UpdateBuilder{
+		update: bson.M{},
+		fields: map[string]string{},
+	}
+}
+
+// UpdateWithError creates a new update builder that starts with an error
+// This is useful for chaining error handling
+func UpdateWithError(err error) *UpdateBuilder {
+	builder := Update()
+	builder.err = err
+	return builder
+}
+
+// Error returns any error that occurred during update building
+func (u *UpdateBuilder) Error() error {
+	return u.err
+}
+
+// Set sets the value of a field
+func (u *UpdateBuilder) Set(key string, value interface{}) *UpdateBuilder {
+	return u.addOperator(UpdateOpSet, key, value)
+}
+
+// Unset removes a field
+func (u *UpdateBuilder) Unset(key string) *UpdateBuilder {
+	return u.addOperator(UpdateOpUnset, key, "")
+}
+
+// Inc increments a numeric field by the given amount
+func (u *UpdateBuilder) Inc(key string, amount interface{}) *UpdateBuilder {
+	if u.err == nil && !isNumber(amount) {
+		u.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("increment for '%s' must be a number", key))
+		return u
+	}
+	return u.addOperator(UpdateOpInc, key, amount)
+}
+
+// Mul multiplies a numeric field by the given factor
+func (u *UpdateBuilder) Mul(key string, factor interface{}) *UpdateBuilder {
+	if u.err == nil && !isNumber(factor) {
+		u.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("factor for '%s' must be a number", key))
+		return u
+	}
+	return u.addOperator(UpdateOpMul, key, factor)
+}
+
+// Min sets a field to the value if the value is less than the current one
+func (u *UpdateBuilder) Min(key string, value interface{}) *UpdateBuilder {
+	return u.addOperator(UpdateOpMin, key, value)
+}
+
+// Max sets a field to the value if the value is greater than the current one
+func (u *UpdateBuilder) Max(key string, value interface{}) *UpdateBuilder {
+	return u.addOperator(UpdateOpMax, key, value)
+}
+
+// Push appends values to an array field
+func (u *UpdateBuilder) Push(key string, values ...interface{}) *UpdateBuilder {
+	if u.err == nil && len(values) == 0 {
+		u.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("push to '%s' requires at least one value", key))
+		return u
+	}
+	return u.addOperator(UpdateOpPush, key, eachValue(values))
+}
+
+// Pull removes all instances of a value, or of values matching a condition, from an array field
+func (u *UpdateBuilder) Pull(key string, value interface{}) *UpdateBuilder {
+	return u.addOperator(UpdateOpPull, key, value)
+}
+
+// AddToSet adds values to an array field unless they are already present
+func (u *UpdateBuilder) AddToSet(key string, values ...interface{}) *UpdateBuilder {
+	if u.err == nil && len(values) == 0 {
+		u.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("addToSet to '%s' requires at least one value", key))
+		return u
+	}
+	return u.addOperator(UpdateOpAddToSet, key, eachValue(values))
+}
+
+// Rename renames a field
+func (u *UpdateBuilder) Rename(key string, newName string) *UpdateBuilder {
+	if u.err == nil && newName == "" {
+		u.err = errors.WithDetails(errors.ErrValidation, fmt.Sprintf("new name for '%s' cannot be empty", key))
+		return u
+	}
+	return u.addOperator(UpdateOpRename, key, newName)
+}
+
+// CurrentDate sets a field to the current date
+func (u *UpdateBuilder) CurrentDate(key string) *UpdateBuilder {
+	return u.addOperator(UpdateOpCurrentDate, key, true)
+}
+
+// Merge adds the operators of another update builder to this one
+func (u *UpdateBuilder) Merge(other *UpdateBuilder) *UpdateBuilder {
+	if u.err != nil || other == nil {
+		return u
+	}
+
+	if other.err != nil {
+		u.err = other.err
+		return u
+	}
+
+	for operator, fields := range other.update {
+		for key, value := range fields.(bson.M) {
+			u.addOperator(operator, key, value)
+		}
+	}
+	return u
+}
+
+// Build returns the update document, or an error if one occurred
+func (u *UpdateBuilder) Build() (bson.M, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	if len(u.update) == 0 {
+		return nil, errors.WithDetails(errors.ErrValidation, "update cannot be empty")
+	}
+
+	return u.update, nil
+}
+
+// addOperator adds a field to an update operator, rejecting conflicting updates of the same field
+func (u *UpdateBuilder) addOperator(operator string, key string, value interface{}) *UpdateBuilder {
+	if u.err != nil {
+		return u
+	}
+
+	if key == "" {
+		u.err = errors.WithDetails(errors.ErrValidation, "key cannot be empty")
+		return u
+	}
+
+	if existing, exists := u.fields[key]; exists && existing != operator {
+		u.err = errors.WithDetails(errors.ErrValidation,
+			fmt.Sprintf("field '%s' is already updated with %s", key, existing))
+		return u
+	}
+	u.fields[key] = operator
+
+	fields, ok := u.update[operator].(bson.M)
+	if !ok {
+		fields = bson.M{}
+		u.update[operator] = fields
+	}
+
+	// Repeated Push or AddToSet calls on the same field accumulate their values
+	if previous, exists := fields[key]; exists && (operator == UpdateOpPush || operator == UpdateOpAddToSet) {
+		each := append(bson.A{}, previous.(bson.M)["$each"].(bson.A)...)
+		each = append(each, value.(bson.M)["$each"].(bson.A)...)
+		value = bson.M{"$each": each}
+	}
+
+	fields[key] = value
+	return u
+}
+
+// eachValue wraps values in an $each modifier
+func eachValue(values []interface{}) bson.M {
+	return bson.M{"$each": bson.A(values)}
+}
+
+// isNumber reports whether a value is of a numeric type
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/isimtekin/merhongo/errors"
 	"reflect"
+	"sort"
 	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Field represents a schema field definition with validation rules
@@ -15,10 +18,33 @@ type Field struct {
 	Default      interface{}
 	Unique       bool
 	Index        bool
-	Min          int
-	Max          int
+	Min          float64
+	Max          float64
 	Enum         []interface{}
 	ValidateFunc func(interface{}) bool
+	// HasMin and HasMax make Min and Max apply when they are zero. Non-zero bounds,
+	// including negative and fractional ones, always apply.
+	HasMin bool
+	HasMax bool
+	// Ref names the model an ObjectID or []ObjectID field references, e.g. "User"
+	Ref string
+	// Schema validates sub-documents, or the elements of an array of sub-documents
+	Schema *Schema
+	// MinLength and MaxLength bound the number of characters of strings; 0 means no bound
+	MinLength int
+	MaxLength int
+	// MinItems and MaxItems bound the number of elements of arrays; 0 means no bound
+	MinItems int
+	MaxItems int
+	// Match is a regular expression strings must match
+	Match string
+	// Format is a built-in format strings must have: FormatEmail, FormatURL, FormatUUID,
+	// FormatObjectID, FormatDate or FormatDateTime
+	Format string
+	// Trim, Lowercase and Uppercase transform strings before they are validated and stored
+	Trim      bool
+	Lowercase bool
+	Uppercase bool
 }
 
 // Schema defines the structure and validation rules for a MongoDB collection
@@ -27,21 +53,38 @@ type Schema struct {
 	Timestamps      bool
 	Collection      string
 	Middlewares     map[string][]func(interface{}) error
+	PostMiddlewares map[string][]func(interface{}) error
+	PreHooks        map[string][]Hook
+	PostHooks       map[string][]Hook
 	CustomValidator func(doc interface{}) error
+	// SoftDelete makes deletes set SoftDeleteField instead of removing documents
+	SoftDelete bool
+	// SoftDeleteField holds the deletion time of soft-deleted documents
+	SoftDeleteField string
+	// Indexes are the indexes declared with WithIndex, in addition to the indexes of fields
+	Indexes []Index
+	// VersionKey is the field holding the document version used for optimistic concurrency control
+	VersionKey string
 	// ModelType holds a reference to the model type for validation purposes
 	ModelType interface{}
 }
 
+// DefaultSoftDeleteField is the field used to mark soft-deleted documents
+const DefaultSoftDeleteField = "deletedAt"
+
 // Option is a function that configures a Schema
 type Option func(*Schema)
 
 // New creates a new Schema with the specified fields and options
 func New(fields map[string]Field, options ...Option) *Schema {
 	schema := &Schema{
-		Fields:      fields,
-		Timestamps:  true,
-		Middlewares: make(map[string][]func(interface{}) error),
-		ModelType:   nil, // Initially empty
+		Fields:          fields,
+		Timestamps:      true,
+		Middlewares:     make(map[string][]func(interface{}) error),
+		PostMiddlewares: make(map[string][]func(interface{}) error),
+		PreHooks:        make(map[string][]Hook),
+		PostHooks:       make(map[string][]Hook),
+		ModelType:       nil, // Initially empty
 	}
 
 	// Apply all provided options
@@ -73,6 +116,25 @@ func WithModelType(modelType interface{}) Option {
 	}
 }
 
+// WithSoftDelete enables soft-delete mode, where deleted documents get a "deletedAt"
+// timestamp instead of being removed and are excluded from queries by default
+func WithSoftDelete() Option {
+	return func(s *Schema) {
+		s.SoftDelete = true
+		if s.SoftDeleteField == "" {
+			s.SoftDeleteField = DefaultSoftDeleteField
+		}
+	}
+}
+
+// WithVersionKey enables optimistic concurrency control. Documents get a version stored
+// in the given field, e.g. "__v", which updates match on and increment.
+func WithVersionKey(key string) Option {
+	return func(s *Schema) {
+		s.VersionKey = key
+	}
+}
+
 // Pre adds a middleware function to be executed before the specified event
 func (s *Schema) Pre(event string, fn func(interface{}) error) {
 	if s.Middlewares[event] == nil {
@@ -81,6 +143,34 @@ func (s *Schema) Pre(event string, fn func(interface{}) error) {
 	s.Middlewares[event] = append(s.Middlewares[event], fn)
 }
 
+// Post adds a middleware function to be executed after the specified event succeeds.
+// Supported events are "save", "update", "delete" and "find"; the function receives
+// the affected document(s) or the operation result.
+func (s *Schema) Post(event string, fn func(interface{}) error) {
+	if s.PostMiddlewares == nil {
+		s.PostMiddlewares = make(map[string][]func(interface{}) error)
+	}
+	s.PostMiddlewares[event] = append(s.PostMiddlewares[event], fn)
+}
+
+// PreHook adds a context-aware middleware function to be executed before the specified event.
+// Supported events are "save", "find", "count", "update", "updateMany", "delete" and "deleteMany".
+func (s *Schema) PreHook(event string, fn Hook) {
+	if s.PreHooks == nil {
+		s.PreHooks = make(map[string][]Hook)
+	}
+	s.PreHooks[event] = append(s.PreHooks[event], fn)
+}
+
+// PostHook adds a context-aware middleware function to be executed after the specified event succeeds.
+// Supported events are the same as for Post.
+func (s *Schema) PostHook(event string, fn Hook) {
+	if s.PostHooks == nil {
+		s.PostHooks = make(map[string][]Hook)
+	}
+	s.PostHooks[event] = append(s.PostHooks[event], fn)
+}
+
 // ValidateDocument validates a document against the schema
 func (s *Schema) ValidateDocument(doc interface{}) error {
 	// Use custom validator if provided
@@ -95,144 +185,304 @@ func (s *Schema) ValidateDocument(doc interface{}) error {
 	return s.defaultValidation(doc)
 }
 
-// defaultValidation performs basic validation based on schema rules
+// defaultValidation performs basic validation based on schema rules. Documents may be
+// structs or maps like bson.M. Every failing field is reported in the returned
+// *errors.ValidationError.
 func (s *Schema) defaultValidation(doc interface{}) error {
 	val := reflect.ValueOf(doc)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
 
-	// Document must be a struct
-	if val.Kind() != reflect.Struct {
-		return errors.WithDetails(errors.ErrValidation, "document must be a struct")
+	// Document must be a struct or a map
+	fields, ok := documentOf(val)
+	if !ok {
+		return errors.WithDetails(errors.ErrValidation, "document must be a struct or a map")
 	}
 
-	// Map to store both lowercase and original bson field names to struct fields
-	bsonToStructField := make(map[string]reflect.Value)
-	// Map to track field names in lowercase for case-insensitive matching
-	lowercaseToOriginal := make(map[string]string)
+	validationErr := &errors.ValidationError{}
+	s.validateFields(fields, "", validationErr)
 
-	// Build the map of bson field names to struct fields
-	t := val.Type()
-	for i := 0; i < t.NumField(); i++ {
-		structField := t.Field(i)
-		bsonTag := structField.Tag.Get("bson")
+	// Report the failures in a stable order, as fields are visited in map order
+	sort.SliceStable(validationErr.Errors, func(i, j int) bool {
+		return validationErr.Errors[i].Path < validationErr.Errors[j].Path
+	})
+	return validationErr.ErrOrNil()
+}
 
-		if bsonTag != "" {
-			parts := strings.Split(bsonTag, ",")
-			if parts[0] != "" && parts[0] != "-" {
-				bsonName := parts[0]
-				bsonToStructField[bsonName] = val.Field(i)
+// validateFields validates the fields of a document against the schema rules, recording
+// failures in validationErr. The prefix is prepended to field names, so sub-document
+// fields read "address.zip".
+func (s *Schema) validateFields(doc document, prefix string, validationErr *errors.ValidationError) {
+	for fieldName, field := range s.Fields {
+		path := prefix + fieldName
+		docField, exists := doc.field(fieldName)
 
-				// Also store lowercase version for case-insensitive matching
-				lowercaseBsonName := strings.ToLower(bsonName)
-				lowercaseToOriginal[lowercaseBsonName] = bsonName
+		// Validate required fields; the other rules don't apply to a missing value
+		if !exists {
+			if field.Required {
+				validationErr.Add(path, "required", nil,
+					fmt.Sprintf("required field '%s' not found in document", path))
 			}
-		} else {
-			// If no bson tag, use the field name
-			fieldName := structField.Name
-			bsonToStructField[fieldName] = val.Field(i)
-
-			// Also store lowercase version
-			lowercaseFieldName := strings.ToLower(fieldName)
-			lowercaseToOriginal[lowercaseFieldName] = fieldName
+			continue
+		}
+		if field.Required && (!docField.IsValid() || docField.IsZero()) {
+			var value interface{}
+			if docField.IsValid() {
+				value = docField.Interface()
+			}
+			validationErr.Add(path, "required", value,
+				fmt.Sprintf("required field '%s' is empty", path))
+			continue
 		}
-	}
 
-	// Validate required fields
-	for fieldName, field := range s.Fields {
-		if !field.Required {
+		// A null map value has nothing else to validate
+		if !docField.IsValid() {
 			continue
 		}
 
-		// Try exact match first
-		docField, exists := bsonToStructField[fieldName]
+		validateField(field, docField, path, validationErr)
+	}
+}
 
-		// If not found, try case-insensitive match
-		if !exists {
-			lowercaseFieldName := strings.ToLower(fieldName)
-			if originalName, found := lowercaseToOriginal[lowercaseFieldName]; found {
-				docField = bsonToStructField[originalName]
-				exists = true
+// validateField checks the value of a present field against its rules
+func validateField(field Field, docField reflect.Value, path string, validationErr *errors.ValidationError) {
+	// Validate the type of the value; the other rules don't apply to a mismatched type
+	if !checkType(field, docField, path, validationErr) {
+		return
+	}
+
+	// Check the value held by interface fields
+	if docField.Kind() == reflect.Interface && !docField.IsNil() {
+		docField = docField.Elem()
+	}
+
+	// Validate Min/Max for numeric fields
+	validateBounds(field, docField, path, validationErr)
+
+	// Validate the number of elements of arrays
+	validateItems(field, docField, path, validationErr)
+
+	// Validate length, pattern and format of strings
+	validateString(field, docField, path, validationErr)
+
+	// Validate enum if present
+	if len(field.Enum) > 0 {
+		found := false
+		for _, enumVal := range field.Enum {
+			enumReflectVal := reflect.ValueOf(enumVal)
+			if reflect.DeepEqual(docField.Interface(), enumReflectVal.Interface()) {
+				found = true
+				break
 			}
 		}
-
-		if !exists {
-			return errors.WithDetails(errors.ErrValidation, fmt.Sprintf("required field '%s' not found in document", fieldName))
+		if !found {
+			validationErr.Add(path, "enum", docField.Interface(),
+				fmt.Sprintf("field '%s' value is not in the allowed enum values", path))
 		}
+	}
 
-		// Check if field is zero value
-		if docField.IsZero() {
-			return errors.WithDetails(errors.ErrValidation, fmt.Sprintf("required field '%s' is empty", fieldName))
+	// Run custom validation function if present
+	if field.ValidateFunc != nil {
+		if !field.ValidateFunc(docField.Interface()) {
+			validationErr.Add(path, "custom", docField.Interface(),
+				fmt.Sprintf("field '%s' failed custom validation", path))
 		}
 	}
 
-	// Validate field types
-	for fieldName, field := range s.Fields {
-		// Try exact match first
-		docField, exists := bsonToStructField[fieldName]
+	// Validate sub-documents against their schema
+	if field.Schema != nil {
+		field.Schema.validateNested(docField, path, validationErr)
+	}
+}
 
-		// If not found, try case-insensitive match
-		if !exists {
-			lowercaseFieldName := strings.ToLower(fieldName)
-			if originalName, found := lowercaseToOriginal[lowercaseFieldName]; found {
-				docField = bsonToStructField[originalName]
-				exists = true
+// validateNested validates a sub-document, or each element of an array of
+// sub-documents, stored at the given path
+func (s *Schema) validateNested(val reflect.Value, path string, validationErr *errors.ValidationError) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	if fields, ok := documentOf(val); ok {
+		if s.CustomValidator != nil {
+			if err := s.CustomValidator(val.Interface()); err != nil {
+				validationErr.Add(path, "custom", val.Interface(), fmt.Sprintf("field '%s': %s", path, err.Error()))
 			}
+			return
 		}
+		s.validateFields(fields, path+".", validationErr)
+		return
+	}
 
-		if !exists {
-			continue
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			s.validateNested(val.Index(i), fmt.Sprintf("%s.%d", path, i), validationErr)
 		}
+	}
+}
 
-		// Validate Min/Max for numeric fields
-		switch docField.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			intVal := docField.Int()
-			if field.Min != 0 && intVal < int64(field.Min) {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' value %d is less than minimum %d", fieldName, intVal, field.Min))
-			}
-			if field.Max != 0 && intVal > int64(field.Max) {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' value %d is greater than maximum %d", fieldName, intVal, field.Max))
-			}
-		case reflect.Float32, reflect.Float64:
-			floatVal := docField.Float()
-			if field.Min != 0 && floatVal < float64(field.Min) {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' value %f is less than minimum %d", fieldName, floatVal, field.Min))
-			}
-			if field.Max != 0 && floatVal > float64(field.Max) {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' value %f is greater than maximum %d", fieldName, floatVal, field.Max))
-			}
+// document gives access to the fields of a struct or map document by their stored name
+type document interface {
+	// field returns the value of a field and whether the document has it. The value
+	// is invalid when a map holds nil.
+	field(name string) (reflect.Value, bool)
+	// setDefault stores the value returned by value in an empty field: a zero-valued
+	// struct field, or a missing or nil map field. value reports false when the field
+	// has no default.
+	setDefault(name string, value func() (interface{}, bool), path string) error
+}
+
+// documentOf returns the fields of a struct, a map with string keys or a bson.D
+func documentOf(val reflect.Value) (document, bool) {
+	switch {
+	case val.Kind() == reflect.Struct:
+		return newFieldLookup(val), true
+	case val.Type() == bsonDType:
+		fields := make(map[string]interface{})
+		for _, e := range val.Interface().(primitive.D) {
+			fields[e.Key] = e.Value
 		}
+		return mapDocument{reflect.ValueOf(fields)}, true
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String:
+		return mapDocument{val}, true
+	}
+	return nil, false
+}
 
-		// Validate enum if present
-		if len(field.Enum) > 0 {
-			found := false
-			for _, enumVal := range field.Enum {
-				enumReflectVal := reflect.ValueOf(enumVal)
-				if reflect.DeepEqual(docField.Interface(), enumReflectVal.Interface()) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' value is not in the allowed enum values", fieldName))
+// mapDocument looks up the fields of a map document
+type mapDocument struct {
+	val reflect.Value
+}
+
+// field returns the value stored under a key, unwrapped from interface{}. Like
+// struct fields, keys are matched case-insensitively when there is no exact match.
+func (d mapDocument) field(name string) (reflect.Value, bool) {
+	value := d.val.MapIndex(reflect.ValueOf(name).Convert(d.val.Type().Key()))
+	if !value.IsValid() {
+		iter := d.val.MapRange()
+		for iter.Next() {
+			if strings.EqualFold(iter.Key().String(), name) {
+				value = iter.Value()
+				break
 			}
 		}
+	}
+	if !value.IsValid() {
+		return reflect.Value{}, false
+	}
+	if value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, true
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+// setDefault stores a default under the key of the field, or under its name when the
+// map doesn't have it. Defaults are converted to the element type of the map.
+func (d mapDocument) setDefault(name string, value func() (interface{}, bool), path string) error {
+	if d.val.IsNil() {
+		return nil
+	}
+	if current, exists := d.field(name); exists && current.IsValid() {
+		return nil
+	}
+	defaultValue, ok := value()
+	if !ok || defaultValue == nil {
+		return nil
+	}
+
+	key := reflect.ValueOf(name).Convert(d.val.Type().Key())
+	iter := d.val.MapRange()
+	for iter.Next() {
+		if strings.EqualFold(iter.Key().String(), name) {
+			key = iter.Key()
+			break
+		}
+	}
+
+	elem := reflect.New(d.val.Type().Elem()).Elem()
+	if elem.Kind() == reflect.Interface {
+		elem.Set(reflect.ValueOf(defaultValue))
+	} else if err := setDefault(elem, defaultValue, path); err != nil {
+		return err
+	}
+	d.val.SetMapIndex(key, elem)
+	return nil
+}
 
-		// Run custom validation function if present
-		if field.ValidateFunc != nil {
-			if !field.ValidateFunc(docField.Interface()) {
-				return errors.WithDetails(errors.ErrValidation,
-					fmt.Sprintf("field '%s' failed custom validation", fieldName))
+// fieldLookup finds the struct fields of a document by their stored name
+type fieldLookup struct {
+	// Map to store both lowercase and original bson field names to struct fields
+	bsonToStructField map[string]reflect.Value
+	// Map to track field names in lowercase for case-insensitive matching
+	lowercaseToOriginal map[string]string
+}
+
+// newFieldLookup maps the bson field names of a struct value to its fields
+func newFieldLookup(val reflect.Value) fieldLookup {
+	lookup := fieldLookup{
+		bsonToStructField:   make(map[string]reflect.Value),
+		lowercaseToOriginal: make(map[string]string),
+	}
+
+	// Build the map of bson field names to struct fields
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		bsonTag := structField.Tag.Get("bson")
+
+		if bsonTag != "" {
+			parts := strings.Split(bsonTag, ",")
+			if parts[0] != "" && parts[0] != "-" {
+				bsonName := parts[0]
+				lookup.bsonToStructField[bsonName] = val.Field(i)
+
+				// Also store lowercase version for case-insensitive matching
+				lowercaseBsonName := strings.ToLower(bsonName)
+				lookup.lowercaseToOriginal[lowercaseBsonName] = bsonName
 			}
+		} else {
+			// If no bson tag, use the field name
+			fieldName := structField.Name
+			lookup.bsonToStructField[fieldName] = val.Field(i)
+
+			// Also store lowercase version
+			lowercaseFieldName := strings.ToLower(fieldName)
+			lookup.lowercaseToOriginal[lowercaseFieldName] = fieldName
 		}
 	}
 
+	return lookup
+}
+
+// field returns the struct field stored under a schema field name
+func (l fieldLookup) field(fieldName string) (reflect.Value, bool) {
+	// Try exact match first
+	if docField, exists := l.bsonToStructField[fieldName]; exists {
+		return docField, true
+	}
+
+	// If not found, try case-insensitive match
+	if originalName, found := l.lowercaseToOriginal[strings.ToLower(fieldName)]; found {
+		return l.bsonToStructField[originalName], true
+	}
+	return reflect.Value{}, false
+}
+
+// setDefault stores a default in a zero-valued struct field
+func (l fieldLookup) setDefault(name string, value func() (interface{}, bool), path string) error {
+	docField, exists := l.field(name)
+	if !exists || !docField.CanSet() || !docField.IsZero() {
+		return nil
+	}
+	if defaultValue, ok := value(); ok {
+		return setDefault(docField, defaultValue, path)
+	}
 	return nil
 }
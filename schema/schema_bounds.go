@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/isimtekin/merhongo/errors"
+)
+
+// MinBound returns the minimum of a numeric field, and whether the field has one
+func (f Field) MinBound() (float64, bool) {
+	return f.Min, f.HasMin || f.Min != 0
+}
+
+// MaxBound returns the maximum of a numeric field, and whether the field has one
+func (f Field) MaxBound() (float64, bool) {
+	return f.Max, f.HasMax || f.Max != 0
+}
+
+// validateBounds checks an integer, unsigned integer or floating point value against
+// the Min and Max bounds of a field
+func validateBounds(field Field, val reflect.Value, path string, validationErr *errors.ValidationError) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	var value interface{}
+	var number float64
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, number = val.Int(), float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, number = val.Uint(), float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		value, number = val.Float(), val.Float()
+	default:
+		return
+	}
+
+	if min, ok := field.MinBound(); ok && number < min {
+		validationErr.Add(path, "min", value,
+			fmt.Sprintf("field '%s' value %v is less than minimum %v", path, value, min))
+	}
+	if max, ok := field.MaxBound(); ok && number > max {
+		validationErr.Add(path, "max", value,
+			fmt.Sprintf("field '%s' value %v is greater than maximum %v", path, value, max))
+	}
+}
+
+// validateItems checks the number of elements of an array against the MinItems and
+// MaxItems bounds of a field
+func validateItems(field Field, val reflect.Value, path string, validationErr *errors.ValidationError) {
+	if field.MinItems == 0 && field.MaxItems == 0 {
+		return
+	}
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	if (val.Kind() != reflect.Slice && val.Kind() != reflect.Array) || val.Type() == bsonDType {
+		return
+	}
+	// A nil slice is stored as null rather than as an empty array
+	if val.Kind() == reflect.Slice && val.IsNil() {
+		return
+	}
+
+	count := val.Len()
+	if field.MinItems > 0 && count < field.MinItems {
+		validationErr.Add(path, "minItems", count,
+			fmt.Sprintf("field '%s' has %d items, less than minimum %d", path, count, field.MinItems))
+	}
+	if field.MaxItems > 0 && count > field.MaxItems {
+		validationErr.Add(path, "maxItems", count,
+			fmt.Sprintf("field '%s' has %d items, more than maximum %d", path, count, field.MaxItems))
+	}
+}
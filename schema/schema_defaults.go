@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/isimtekin/merhongo/errors"
+)
+
+// DefaultValue resolves the default of a field, calling it when it is a
+// func() interface{}. It reports false when the field has no default.
+func (f Field) DefaultValue() (interface{}, bool) {
+	switch d := f.Default.(type) {
+	case nil:
+		return nil, false
+	case func() interface{}:
+		return d(), true
+	default:
+		return d, true
+	}
+}
+
+// ApplyDefaults sets the empty fields of a document to their schema defaults, including
+// the fields of sub-documents with a nested schema. The document is a pointer to a struct,
+// whose zero-valued fields are empty, or a map like bson.M, whose missing or nil fields
+// are empty. Defaults are converted to the type of the field, and pointer fields are allocated.
+func (s *Schema) ApplyDefaults(doc interface{}) error {
+	val := reflect.ValueOf(doc)
+	if val.Kind() == reflect.Ptr && val.Elem().Kind() == reflect.Map {
+		val = val.Elem()
+	}
+
+	fields, ok := defaultsDocumentOf(val)
+	if !ok {
+		return errors.WithDetails(errors.ErrValidation, "document must be a pointer to a struct or a map")
+	}
+	return s.applyDefaults(fields, "")
+}
+
+// defaultsDocumentOf returns the fields of a document its defaults can be stored in: a
+// settable struct or a map with string keys. The fields of a bson.D are copied by
+// documentOf, so it isn't one of them.
+func defaultsDocumentOf(val reflect.Value) (document, bool) {
+	if val.Kind() == reflect.Ptr && !val.IsNil() && val.Elem().Kind() == reflect.Struct {
+		val = val.Elem()
+	}
+	if !val.IsValid() || val.Type() == bsonDType || val.Kind() == reflect.Struct && !val.CanSet() {
+		return nil, false
+	}
+	return documentOf(val)
+}
+
+// applyDefaults sets the defaults of a document. The prefix is prepended to field
+// names in error messages.
+func (s *Schema) applyDefaults(doc document, prefix string) error {
+	for fieldName, field := range s.Fields {
+		path := prefix + fieldName
+
+		if err := doc.setDefault(fieldName, field.DefaultValue, path); err != nil {
+			return err
+		}
+
+		if field.Schema != nil {
+			if docField, exists := doc.field(fieldName); exists && docField.IsValid() {
+				if err := field.Schema.applyNestedDefaults(docField, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyNestedDefaults sets the defaults of a sub-document, or of each element of
+// an array of sub-documents
+func (s *Schema) applyNestedDefaults(val reflect.Value, path string) error {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if fields, ok := defaultsDocumentOf(val); ok {
+		return s.applyDefaults(fields, path+".")
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		if val.Type() == bsonDType {
+			return nil
+		}
+		for i := 0; i < val.Len(); i++ {
+			if err := s.applyNestedDefaults(val.Index(i), fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setDefault stores a default value in a field, converting it to the field's type
+func setDefault(field reflect.Value, value interface{}, path string) error {
+	if value == nil {
+		return nil
+	}
+
+	// Point pointer fields to a new value holding the default
+	target := field
+	allocate := field.Kind() == reflect.Ptr && !reflect.TypeOf(value).AssignableTo(field.Type())
+	if allocate {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	defaultVal := reflect.ValueOf(value)
+	switch {
+	case defaultVal.Type().AssignableTo(target.Type()):
+		target.Set(defaultVal)
+	case defaultVal.Type().ConvertibleTo(target.Type()) &&
+		(defaultVal.Kind() == target.Kind() || isNumeric(defaultVal.Kind()) && isNumeric(target.Kind())):
+		target.Set(defaultVal.Convert(target.Type()))
+	default:
+		return errors.WithDetails(errors.ErrValidation,
+			fmt.Sprintf("default value of field '%s' has type %s, expected %s", path, defaultVal.Type(), field.Type()))
+	}
+
+	if allocate {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+// isNumeric reports whether a kind is an integer or floating point number
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseDefault parses the default of a `schema:"default=..."` tag for a scalar type.
+// It reports false when the type is not a scalar or the value does not parse.
+func parseDefault(raw string, t reflect.Type) (interface{}, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var value interface{}
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		value = raw
+	case reflect.Bool:
+		value, err = strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err = strconv.ParseInt(raw, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err = strconv.ParseUint(raw, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		value, err = strconv.ParseFloat(raw, t.Bits())
+	default:
+		return nil, false
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	// Store the default with the field's own type, e.g. a custom string type
+	return reflect.ValueOf(value).Convert(t).Interface(), true
+}
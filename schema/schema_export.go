@@ -0,0 +1,321 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JSONSchemaDialect is the $schema of documents exported by ExportJSONSchema
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// objectIDPattern matches the hex form of ObjectIDs in JSON payloads
+const objectIDPattern = "^[0-9a-fA-F]{24}$"
+
+// ExportOptions configures ExportJSONSchema and ExportOpenAPI
+type ExportOptions struct {
+	// Title of the exported schema, e.g. "User". OpenAPI components of recursive
+	// schemas reference themselves by their title.
+	Title string
+	// ID is the $id of an exported JSON Schema document
+	ID string
+}
+
+// ExportJSONSchema renders the schema as a JSON Schema draft 2020-12 document for API
+// documentation. Required fields, types, formats, numeric, length and item bounds,
+// patterns, enums, defaults and nested schemas are exported; recursive sub-documents
+// reference their schema with an $anchor. Properties are named like ToJSONSchema names them.
+func (s *Schema) ExportJSONSchema(opts ...ExportOptions) map[string]interface{} {
+	var o ExportOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	e := newExporter(false)
+	result := map[string]interface{}{"$schema": JSONSchemaDialect}
+	if o.ID != "" {
+		result["$id"] = o.ID
+	}
+	if o.Title != "" {
+		result["title"] = o.Title
+	}
+	for key, value := range e.object(s, s.modelStructType()) {
+		result[key] = value
+	}
+	return result
+}
+
+// ExportOpenAPI renders the schema as an OpenAPI 3 component schema, to be listed
+// under components.schemas. It exports the same rules as ExportJSONSchema within
+// the OpenAPI 3.0 subset: a recursive sub-document references the component by its
+// title, or is an unconstrained object when the schema has no title.
+func (s *Schema) ExportOpenAPI(opts ...ExportOptions) map[string]interface{} {
+	var o ExportOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	e := newExporter(true)
+	if o.Title != "" {
+		e.rootRef = "#/components/schemas/" + o.Title
+	}
+	result := e.object(s, s.modelStructType())
+	if o.Title != "" {
+		result["title"] = o.Title
+	}
+	return result
+}
+
+// modelStructType returns the type of the schema's ModelType, if any
+func (s *Schema) modelStructType() reflect.Type {
+	if s.ModelType == nil {
+		return nil
+	}
+	return reflect.TypeOf(s.ModelType)
+}
+
+// exporter renders schemas as JSON Schema or OpenAPI schema objects
+type exporter struct {
+	openAPI bool
+	// rootRef references the exported schema from its recursive sub-documents
+	rootRef string
+	root    *Schema
+	// converting tracks the schemas being exported, to detect recursion
+	converting map[*Schema]bool
+	// anchors names the nested schemas referenced by recursive sub-documents
+	anchors map[*Schema]string
+}
+
+// newExporter returns an exporter of JSON Schema or, with openAPI, of OpenAPI schemas
+func newExporter(openAPI bool) *exporter {
+	e := &exporter{
+		openAPI:    openAPI,
+		converting: make(map[*Schema]bool),
+		anchors:    make(map[*Schema]string),
+	}
+	if !openAPI {
+		e.rootRef = "#"
+	}
+	return e
+}
+
+// object renders a schema as an object schema. The struct type storing its documents,
+// which may be nil, names the properties and types fields without a Type.
+func (e *exporter) object(s *Schema, structType reflect.Type) map[string]interface{} {
+	if e.root == nil {
+		e.root = s
+	}
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType != nil && structType.Kind() != reflect.Struct {
+		structType = nil
+	}
+
+	// Recursive sub-documents reference the schema being exported
+	if e.converting[s] {
+		return e.reference(s, structType)
+	}
+
+	result := map[string]interface{}{"type": "object"}
+	if s.CustomValidator != nil {
+		return result
+	}
+	e.converting[s] = true
+	defer delete(e.converting, s)
+
+	properties := make(map[string]interface{})
+	var required []string
+	for fieldName, field := range s.Fields {
+		name := fieldName
+		var goType reflect.Type
+		if structField, storedName, ok := storedField(structType, fieldName); ok {
+			name, goType = storedName, structField.Type
+		}
+
+		properties[name] = e.property(field, goType)
+		if field.Required {
+			required = append(required, name)
+		}
+	}
+
+	if len(properties) > 0 {
+		result["properties"] = properties
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		result["required"] = required
+	}
+	if anchor, referenced := e.anchors[s]; referenced && s != e.root {
+		result["$anchor"] = anchor
+	}
+	return result
+}
+
+// reference returns the schema of a recursive sub-document
+func (e *exporter) reference(s *Schema, structType reflect.Type) map[string]interface{} {
+	if s == e.root && e.rootRef != "" {
+		return map[string]interface{}{"$ref": e.rootRef}
+	}
+	if e.openAPI {
+		return map[string]interface{}{"type": "object"}
+	}
+
+	anchor, exists := e.anchors[s]
+	if !exists {
+		anchor = fmt.Sprintf("schema%d", len(e.anchors)+1)
+		if structType != nil && structType.Name() != "" {
+			anchor = structType.Name()
+		}
+		e.anchors[s] = anchor
+	}
+	return map[string]interface{}{"$ref": "#" + anchor}
+}
+
+// property renders the rules of a field. The Go type of the struct field storing it,
+// if known, is used when the field has no Type.
+func (e *exporter) property(f Field, goType reflect.Type) map[string]interface{} {
+	fieldType := goType
+	if f.Type != nil {
+		fieldType = reflect.TypeOf(f.Type)
+	}
+	for fieldType != nil && fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	var nestedType reflect.Type
+	if goType != nil {
+		nestedType, _ = subDocumentType(goType)
+	}
+
+	isArray := fieldType != nil && isArrayType(fieldType)
+	var property map[string]interface{}
+	switch {
+	case f.Schema != nil && isArray:
+		property = map[string]interface{}{"type": "array", "items": e.object(f.Schema, nestedType)}
+	case f.Schema != nil:
+		property = e.object(f.Schema, nestedType)
+	default:
+		property = e.valueType(fieldType)
+	}
+
+	if format, pattern := exportFormat(f.Format); format != "" {
+		property["format"] = format
+	} else if pattern != "" {
+		property["pattern"] = pattern
+	}
+	f.addConstraints(property)
+
+	// Defaults computed by a function depend on the time of the insert
+	if _, computed := f.Default.(func() interface{}); !computed && f.Default != nil {
+		property["default"] = f.Default
+	}
+	return property
+}
+
+// addConstraints adds the rules of a field that JSON Schema and $jsonSchema share the
+// keywords of: bounds, lengths, item counts, the Match pattern and the enum
+func (f Field) addConstraints(property map[string]interface{}) {
+	if min, ok := f.MinBound(); ok {
+		property["minimum"] = min
+	}
+	if max, ok := f.MaxBound(); ok {
+		property["maximum"] = max
+	}
+	if f.MinLength > 0 {
+		property["minLength"] = f.MinLength
+	}
+	if f.MaxLength > 0 {
+		property["maxLength"] = f.MaxLength
+	}
+	if f.MinItems > 0 {
+		property["minItems"] = f.MinItems
+	}
+	if f.MaxItems > 0 {
+		property["maxItems"] = f.MaxItems
+	}
+	if f.Match != "" {
+		property["pattern"] = f.Match
+	}
+	if len(f.Enum) > 0 {
+		property["enum"] = append([]interface{}{}, f.Enum...)
+	}
+}
+
+// valueType renders the JSON type of values of a Go type, as they appear in payloads
+func (e *exporter) valueType(t reflect.Type) map[string]interface{} {
+	property := make(map[string]interface{})
+	if t == nil {
+		return property
+	}
+
+	switch t {
+	case timeType, dateTimeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(primitive.ObjectID{}):
+		return map[string]interface{}{"type": "string", "pattern": objectIDPattern}
+	case bsonDType, reflect.TypeOf(primitive.M{}):
+		return map[string]interface{}{"type": "object"}
+	case bsonAType:
+		return map[string]interface{}{"type": "array"}
+	}
+	if isBSONType(t) {
+		return property
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		property["type"] = "string"
+	case reflect.Bool:
+		property["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		property["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		property["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		// Byte slices are base64 strings in JSON
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			property["type"] = "string"
+			if e.openAPI {
+				property["format"] = "byte"
+			} else {
+				property["contentEncoding"] = "base64"
+			}
+			break
+		}
+		property["type"] = "array"
+		elemType := t.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if items := e.valueType(elemType); len(items) > 0 {
+			property["items"] = items
+		}
+	case reflect.Map, reflect.Struct:
+		property["type"] = "object"
+	}
+	return property
+}
+
+// exportFormat returns the JSON Schema format of a built-in string format, or the
+// pattern of formats JSON Schema doesn't define
+func exportFormat(format string) (string, string) {
+	switch format {
+	case FormatEmail:
+		return "email", ""
+	case FormatURL:
+		return "uri", ""
+	case FormatUUID:
+		return "uuid", ""
+	case FormatDate:
+		return "date", ""
+	case FormatDateTime:
+		return "date-time", ""
+	case FormatObjectID:
+		return "", objectIDPattern
+	}
+	return "", ""
+}
@@ -3,6 +3,7 @@ package schema
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,11 +12,32 @@ import (
 
 // SchemaTag represents the schema tag and its options
 type SchemaTag struct {
-	Required bool
-	Unique   bool
-	Min      int
-	Max      int
-	Index    bool
+	Required   bool
+	Unique     bool
+	Min        float64
+	Max        float64
+	HasMin     bool
+	HasMax     bool
+	MinItems   int
+	MaxItems   int
+	Index      bool
+	SoftDelete bool
+	Ref        string
+	Populate   string
+	Default    string
+	MinLength  int
+	MaxLength  int
+	Match      string
+	Format     string
+	Trim       bool
+	Lowercase  bool
+	Uppercase  bool
+	// IndexType is the direction or type of an `index=` option: "1", "-1", "text", "2dsphere" or "hashed"
+	IndexType string
+	Sparse    bool
+	TTL       *int32
+	Compound  string
+	Weight    int
 }
 
 // GenerateFromStruct automatically generates a Schema from a struct type
@@ -33,8 +55,18 @@ func GenerateFromStruct(structType interface{}, options ...Option) *Schema {
 		panic("GenerateFromStruct: input must be a struct or pointer to struct")
 	}
 
-	// Create a new schema
-	fields := make(map[string]Field)
+	return generateSchema(t, make(map[reflect.Type]*Schema), options...)
+}
+
+// generateSchema generates the schema of a struct type. Schemas being generated are
+// tracked by type, so recursive structs reference their own schema.
+func generateSchema(t reflect.Type, generating map[reflect.Type]*Schema, options ...Option) *Schema {
+	// Create the schema up front, so nested fields of the same type can reference it
+	schema := New(make(map[string]Field), options...)
+	generating[t] = schema
+	fields := schema.Fields
+	softDeleteField := ""
+	indexes := &tagIndexes{}
 
 	// Process each field in the struct
 	for i := 0; i < t.NumField(); i++ {
@@ -55,14 +87,17 @@ func GenerateFromStruct(structType interface{}, options ...Option) *Schema {
 		if field.Anonymous {
 			// For embedded structs, process their fields recursively
 			if field.Type.Kind() == reflect.Struct {
-				// Create a zero value of this type to pass to GenerateFromStruct
-				embeddedValue := reflect.New(field.Type).Elem().Interface()
-				embeddedSchema := GenerateFromStruct(embeddedValue)
+				embeddedSchema := generateSchema(field.Type, generating)
 
 				// Add all fields from the embedded struct to our schema
 				for embeddedFieldName, embeddedField := range embeddedSchema.Fields {
 					fields[embeddedFieldName] = embeddedField
 				}
+
+				if embeddedSchema.SoftDelete {
+					softDeleteField = embeddedSchema.SoftDeleteField
+				}
+				indexes.declared = append(indexes.declared, embeddedSchema.Indexes...)
 			}
 			// Skip anonymous fields that aren't structs
 			continue
@@ -71,17 +106,50 @@ func GenerateFromStruct(structType interface{}, options ...Option) *Schema {
 		// Parse schema tag
 		schemaTag := parseSchemaTag(field.Tag.Get("schema"))
 
+		// Fields receiving populated documents are not stored
+		if schemaTag.Populate != "" {
+			continue
+		}
+
 		// Get zero value for the field type
 		zeroVal := GetZeroValue(field.Type)
 
 		// Create field definition
 		fieldDef := Field{
-			Type:     zeroVal,
-			Required: schemaTag.Required,
-			Unique:   schemaTag.Unique,
-			Index:    schemaTag.Index || schemaTag.Unique,
-			Min:      schemaTag.Min,
-			Max:      schemaTag.Max,
+			Type:      zeroVal,
+			Required:  schemaTag.Required,
+			Unique:    schemaTag.Unique,
+			Index:     schemaTag.Index || schemaTag.Unique,
+			Min:       schemaTag.Min,
+			Max:       schemaTag.Max,
+			HasMin:    schemaTag.HasMin,
+			HasMax:    schemaTag.HasMax,
+			MinItems:  schemaTag.MinItems,
+			MaxItems:  schemaTag.MaxItems,
+			Ref:       schemaTag.Ref,
+			MinLength: schemaTag.MinLength,
+			MaxLength: schemaTag.MaxLength,
+			Match:     schemaTag.Match,
+			Format:    schemaTag.Format,
+			Trim:      schemaTag.Trim,
+			Lowercase: schemaTag.Lowercase,
+			Uppercase: schemaTag.Uppercase,
+		}
+
+		// Scalar fields may declare a default value
+		if schemaTag.Default != "" {
+			if value, ok := parseDefault(schemaTag.Default, field.Type); ok {
+				fieldDef.Default = value
+			}
+		}
+
+		// Sub-documents and arrays of sub-documents get a nested schema
+		if subType, ok := subDocumentType(field.Type); ok {
+			if nested, exists := generating[subType]; exists {
+				fieldDef.Schema = nested
+			} else {
+				fieldDef.Schema = generateSchema(subType, generating, WithTimestamps(false))
+			}
 		}
 
 		// Extract field name from bson tag if present, otherwise use the struct field name
@@ -97,14 +165,43 @@ func GenerateFromStruct(structType interface{}, options ...Option) *Schema {
 		// Add field to schema using either the bson tag name or the struct field name
 		// Note: We include fields with bson:"-" in the schema because it's part of the test requirements
 		fields[fieldName] = fieldDef
+		indexes.add(fieldName, schemaTag)
+
+		if schemaTag.SoftDelete {
+			softDeleteField = fieldName
+		}
 	}
 
-	// Create schema with the fields
-	schema := New(fields, options...)
+	// Indexes declared by the tags come before those of the options
+	schema.Indexes = append(indexes.list(), schema.Indexes...)
+
+	// A field tagged with softdelete enables soft-delete mode on that field
+	if softDeleteField != "" {
+		schema.SoftDelete = true
+		schema.SoftDeleteField = softDeleteField
+	}
 
 	return schema
 }
 
+// subDocumentType returns the struct type stored as a sub-document by a field of the
+// given type: a struct, a pointer to a struct, or a slice or array of either
+func subDocumentType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Time and BSON types are stored as values, not sub-documents
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) ||
+		strings.HasPrefix(t.PkgPath(), "go.mongodb.org/mongo-driver/") {
+		return nil, false
+	}
+	return t, true
+}
+
 // parseSchemaTag parses the schema tag into a SchemaTag struct
 func parseSchemaTag(tag string) SchemaTag {
 	result := SchemaTag{}
@@ -114,8 +211,8 @@ func parseSchemaTag(tag string) SchemaTag {
 	}
 
 	options := strings.Split(tag, ",")
-	for _, opt := range options {
-		opt = strings.TrimSpace(opt)
+	for i := 0; i < len(options); i++ {
+		opt := strings.TrimSpace(options[i])
 		switch {
 		case opt == "required":
 			result.Required = true
@@ -123,20 +220,84 @@ func parseSchemaTag(tag string) SchemaTag {
 			result.Unique = true
 		case opt == "index":
 			result.Index = true
+		case strings.HasPrefix(opt, "index="):
+			// Other directions and types are declared as indexes of the schema
+			result.IndexType = strings.TrimPrefix(opt, "index=")
+			result.Index = result.IndexType == "1"
+		case opt == "sparse":
+			result.Sparse = true
+		case strings.HasPrefix(opt, "ttl="):
+			if ttl, err := strconv.ParseInt(strings.TrimPrefix(opt, "ttl="), 10, 32); err == nil {
+				seconds := int32(ttl)
+				result.TTL = &seconds
+			}
+		case strings.HasPrefix(opt, "compound="):
+			result.Compound = strings.TrimPrefix(opt, "compound=")
+		case strings.HasPrefix(opt, "weight="):
+			fmt.Sscanf(opt, "weight=%d", &result.Weight)
+		case opt == "softdelete":
+			result.SoftDelete = true
+		case strings.HasPrefix(opt, "ref="):
+			result.Ref = strings.TrimPrefix(opt, "ref=")
+		case strings.HasPrefix(opt, "populate="):
+			result.Populate = strings.TrimPrefix(opt, "populate=")
+		case strings.HasPrefix(opt, "default="):
+			result.Default = strings.TrimPrefix(opt, "default=")
+		case opt == "trim":
+			result.Trim = true
+		case opt == "lowercase":
+			result.Lowercase = true
+		case opt == "uppercase":
+			result.Uppercase = true
+		case strings.HasPrefix(opt, "minlen="):
+			fmt.Sscanf(opt, "minlen=%d", &result.MinLength)
+		case strings.HasPrefix(opt, "maxlen="):
+			fmt.Sscanf(opt, "maxlen=%d", &result.MaxLength)
+		case strings.HasPrefix(opt, "format="):
+			result.Format = strings.TrimPrefix(opt, "format=")
+		case strings.HasPrefix(opt, "match="):
+			// Patterns may contain commas, so the following parts belong to the
+			// pattern until one of them is a tag option
+			pattern := strings.TrimPrefix(opt, "match=")
+			for i+1 < len(options) && !isTagOption(strings.TrimSpace(options[i+1])) {
+				i++
+				pattern += "," + options[i]
+			}
+			result.Match = pattern
+		case strings.HasPrefix(opt, "minitems="):
+			fmt.Sscanf(opt, "minitems=%d", &result.MinItems)
+		case strings.HasPrefix(opt, "maxitems="):
+			fmt.Sscanf(opt, "maxitems=%d", &result.MaxItems)
 		case strings.HasPrefix(opt, "min="):
-			var min int
-			fmt.Sscanf(opt, "min=%d", &min)
-			result.Min = min
+			// Bounds may be zero, negative or fractional; invalid ones are ignored
+			if min, err := strconv.ParseFloat(strings.TrimPrefix(opt, "min="), 64); err == nil {
+				result.Min, result.HasMin = min, true
+			}
 		case strings.HasPrefix(opt, "max="):
-			var max int
-			fmt.Sscanf(opt, "max=%d", &max)
-			result.Max = max
+			if max, err := strconv.ParseFloat(strings.TrimPrefix(opt, "max="), 64); err == nil {
+				result.Max, result.HasMax = max, true
+			}
 		}
 	}
 
 	return result
 }
 
+// isTagOption reports whether a part of a schema tag is one of its options
+func isTagOption(part string) bool {
+	switch part {
+	case "required", "unique", "index", "sparse", "softdelete", "trim", "lowercase", "uppercase":
+		return true
+	}
+	for _, prefix := range []string{"ref=", "populate=", "default=", "min=", "max=", "minitems=", "maxitems=",
+		"minlen=", "maxlen=", "match=", "format=", "index=", "ttl=", "compound=", "weight="} {
+		if strings.HasPrefix(part, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetZeroValue returns a zero value for the given type
 func GetZeroValue(t reflect.Type) interface{} {
 	// Handle primitive.ObjectID specially, it's a common case
@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HookContext carries the metadata of a model operation to context-aware middlewares
+type HookContext struct {
+	// Ctx is the context the operation was called with
+	Ctx context.Context
+	// Model is the name of the model executing the operation
+	Model string
+	// Operation is the model method being executed, e.g. "UpdateById"
+	Operation string
+	// Event is the middleware event being fired, e.g. "update"
+	Event string
+	// Filter is the query filter; pre hooks may modify it, e.g. to add tenant scoping
+	Filter bson.M
+	// Update is the update document; pre hooks may modify it
+	Update bson.M
+	// Document is the document being saved, if any
+	Document interface{}
+	// Result is the value post hooks receive: the affected document(s) or the operation result
+	Result interface{}
+	// Session is the active session when the operation runs inside one
+	Session mongo.Session
+}
+
+// Hook is a context-aware middleware function
+type Hook func(hc *HookContext) error
@@ -0,0 +1,166 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index types of Index.Keys values, besides the directions 1 and -1
+const (
+	IndexText     = "text"
+	Index2DSphere = "2dsphere"
+	IndexHashed   = "hashed"
+)
+
+// Index declares an index of the schema's collection
+type Index struct {
+	// Keys lists the indexed fields in order with their direction, 1 or -1, or their
+	// index type: IndexText, Index2DSphere or IndexHashed
+	Keys bson.D
+	// Name of the index; MongoDB derives one from the keys when empty, e.g. "userId_1_createdAt_-1"
+	Name   string
+	Unique bool
+	Sparse bool
+	// ExpireAfterSeconds makes a TTL index, which removes documents once the date
+	// stored in its single key is older than the given number of seconds
+	ExpireAfterSeconds *int32
+	// Weights are the relative weights of the fields of a text index
+	Weights bson.M
+	// DefaultLanguage is the language of a text index, e.g. "english"
+	DefaultLanguage string
+	// PartialFilter limits the index to the documents matching the filter expression
+	PartialFilter bson.M
+	// Collation sets the language rules of string comparisons in the index
+	Collation *options.Collation
+}
+
+// KeyName returns the name MongoDB derives from the keys of an index
+func (i Index) KeyName() string {
+	parts := make([]string, 0, len(i.Keys))
+	for _, key := range i.Keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// IndexName returns the name of an index, or the name derived from its keys when it has none
+func (i Index) IndexName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return i.KeyName()
+}
+
+// WithIndex declares indexes of the schema's collection, in addition to the
+// single-field indexes of fields with Index or Unique set
+func WithIndex(indexes ...Index) Option {
+	return func(s *Schema) {
+		s.Indexes = append(s.Indexes, indexes...)
+	}
+}
+
+// AllIndexes returns the indexes the schema declares: an ascending index for each
+// field with Index or Unique set, in field order, followed by the Indexes of WithIndex.
+// A declared index whose only key is a field replaces the index of that field.
+func (s *Schema) AllIndexes() []Index {
+	replaced := make(map[string]bool)
+	for _, index := range s.Indexes {
+		if len(index.Keys) == 1 {
+			replaced[index.Keys[0].Key] = true
+		}
+	}
+
+	fieldNames := make([]string, 0, len(s.Fields))
+	for fieldName, field := range s.Fields {
+		if (field.Index || field.Unique) && !replaced[fieldName] {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+	sort.Strings(fieldNames)
+
+	indexes := make([]Index, 0, len(fieldNames)+len(s.Indexes))
+	for _, fieldName := range fieldNames {
+		indexes = append(indexes, Index{
+			Keys:   bson.D{{Key: fieldName, Value: 1}},
+			Unique: s.Fields[fieldName].Unique,
+		})
+	}
+	return append(indexes, s.Indexes...)
+}
+
+// tagIndexes collects the indexes declared by the schema tags of a struct's fields
+type tagIndexes struct {
+	declared []Index
+	// positions holds the position in declared of the text index and of the compound
+	// indexes, which are built from several fields
+	positions map[string]int
+}
+
+// add declares the indexes of a field's tag
+func (t *tagIndexes) add(fieldName string, tag SchemaTag) {
+	switch {
+	case tag.IndexType == IndexText:
+		// A collection has a single text index, covering every text field
+		index := t.shared("$text", Index{})
+		index.Keys = append(index.Keys, bson.E{Key: fieldName, Value: IndexText})
+		if tag.Weight > 0 {
+			if index.Weights == nil {
+				index.Weights = bson.M{}
+			}
+			index.Weights[fieldName] = tag.Weight
+		}
+	case tag.IndexType != "" && tag.IndexType != "1" || tag.Sparse || tag.TTL != nil:
+		t.declared = append(t.declared, Index{
+			Keys:               bson.D{{Key: fieldName, Value: indexKeyValue(tag.IndexType)}},
+			Unique:             tag.Unique,
+			Sparse:             tag.Sparse,
+			ExpireAfterSeconds: tag.TTL,
+		})
+	}
+
+	// Fields sharing a compound name form one index, in field order
+	if tag.Compound != "" {
+		name, value := tag.Compound, ""
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			name, value = name[:i], name[i+1:]
+		}
+		index := t.shared("compound:"+name, Index{Name: name})
+		index.Keys = append(index.Keys, bson.E{Key: fieldName, Value: indexKeyValue(value)})
+	}
+}
+
+// shared returns the index stored under a key, declaring it when it doesn't exist yet
+func (t *tagIndexes) shared(key string, index Index) *Index {
+	if t.positions == nil {
+		t.positions = make(map[string]int)
+	}
+	pos, exists := t.positions[key]
+	if !exists {
+		pos = len(t.declared)
+		t.positions[key] = pos
+		t.declared = append(t.declared, index)
+	}
+	return &t.declared[pos]
+}
+
+// list returns the declared indexes
+func (t *tagIndexes) list() []Index {
+	return t.declared
+}
+
+// indexKeyValue returns the value of an index key from its tag form: a direction, 1 by
+// default, or an index type like "2dsphere"
+func indexKeyValue(value string) interface{} {
+	if value == "" {
+		return 1
+	}
+	if direction, err := strconv.Atoi(value); err == nil {
+		return direction
+	}
+	return value
+}
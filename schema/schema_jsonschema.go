@@ -0,0 +1,256 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ToJSONSchema converts the schema rules into a MongoDB $jsonSchema document, so the
+// server enforces them on writes that don't go through ValidateDocument. Required
+// fields, types, numeric, length and item bounds, patterns, enums and nested schemas
+// are converted. Formats, transforms and ValidateFunc have no $jsonSchema equivalent
+// and are left out, and a schema with a CustomValidator only requires an object.
+//
+// Properties are named as fields are stored. When the schema has a ModelType, field
+// names are resolved through the bson tags of its struct, as documents are validated.
+func (s *Schema) ToJSONSchema() bson.M {
+	var structType reflect.Type
+	if s.ModelType != nil {
+		structType = reflect.TypeOf(s.ModelType)
+	}
+	return s.jsonSchema(structType, make(map[*Schema]bool))
+}
+
+// jsonSchema converts the schema of a document stored from the given struct type,
+// which may be nil. Schemas being converted are tracked, as $jsonSchema has no
+// references to describe recursive documents with.
+func (s *Schema) jsonSchema(structType reflect.Type, converting map[*Schema]bool) bson.M {
+	result := bson.M{"bsonType": "object"}
+	if s.CustomValidator != nil || converting[s] {
+		return result
+	}
+	converting[s] = true
+	defer delete(converting, s)
+
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType != nil && structType.Kind() != reflect.Struct {
+		structType = nil
+	}
+
+	properties := bson.M{}
+	var required []string
+	for fieldName, field := range s.Fields {
+		name := fieldName
+		var goType reflect.Type
+		if structField, storedName, ok := storedField(structType, fieldName); ok {
+			name, goType = storedName, structField.Type
+		}
+
+		properties[name] = field.jsonSchemaProperty(goType, converting)
+		if field.Required {
+			required = append(required, name)
+		}
+	}
+
+	if len(properties) > 0 {
+		result["properties"] = properties
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		result["required"] = required
+	}
+	return result
+}
+
+// jsonSchemaProperty converts the rules of a field. The Go type of the struct field
+// storing it, if known, is used when the field has no Type.
+func (f Field) jsonSchemaProperty(goType reflect.Type, converting map[*Schema]bool) bson.M {
+	property := bson.M{}
+
+	fieldType := goType
+	if f.Type != nil {
+		fieldType = reflect.TypeOf(f.Type)
+	}
+	for fieldType != nil && fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	// Optional fields may be null, like the nil values ValidateDocument skips
+	if types := bsonTypes(fieldType); len(types) > 0 {
+		if !f.Required {
+			types = append(types, "null")
+		}
+		if len(types) == 1 {
+			property["bsonType"] = types[0]
+		} else {
+			property["bsonType"] = types
+		}
+	}
+	// Integer fields only accept whole doubles
+	if isIntegerType(fieldType) {
+		property["multipleOf"] = 1
+	}
+
+	f.addConstraints(property)
+	// The enum of an optional field accepts null too
+	if enum, ok := property["enum"].([]interface{}); ok {
+		if !f.Required {
+			enum = append(enum, nil)
+		}
+		property["enum"] = bson.A(enum)
+	}
+
+	// Sub-documents follow their nested schema; arrays describe their elements
+	isArray := fieldType != nil && isArrayType(fieldType)
+	var elemType reflect.Type
+	if isArray && fieldType != bsonAType {
+		elemType = fieldType.Elem()
+	}
+	var nestedType reflect.Type
+	if goType != nil {
+		nestedType, _ = subDocumentType(goType)
+	}
+
+	switch {
+	case f.Schema != nil && isArray:
+		property["items"] = f.Schema.jsonSchema(nestedType, converting)
+	case f.Schema != nil:
+		for key, value := range f.Schema.jsonSchema(nestedType, converting) {
+			if key != "bsonType" {
+				property[key] = value
+			}
+		}
+	case elemType != nil:
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if types := bsonTypes(elemType); len(types) == 1 {
+			property["items"] = bson.M{"bsonType": types[0]}
+		} else if len(types) > 1 {
+			property["items"] = bson.M{"bsonType": types}
+		}
+	}
+
+	return property
+}
+
+// bsonTypes returns the $jsonSchema BSON types a value of a Go type may be stored
+// as, matching the types ValidateDocument accepts
+func bsonTypes(t reflect.Type) []string {
+	if t == nil {
+		return nil
+	}
+
+	switch t {
+	case timeType, dateTimeType:
+		return []string{"date"}
+	case reflect.TypeOf(primitive.ObjectID{}):
+		return []string{"objectId"}
+	case reflect.TypeOf(primitive.Decimal128{}):
+		return []string{"decimal"}
+	case reflect.TypeOf(primitive.Binary{}):
+		return []string{"binData"}
+	case reflect.TypeOf(primitive.Timestamp{}):
+		return []string{"timestamp"}
+	case reflect.TypeOf(primitive.Regex{}):
+		return []string{"regex"}
+	case bsonDType, reflect.TypeOf(primitive.M{}):
+		return []string{"object"}
+	case bsonAType:
+		return []string{"array"}
+	}
+	if isBSONType(t) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return []string{"string"}
+	case reflect.Bool:
+		return []string{"bool"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Whole doubles are accepted too, like decoded JSON numbers are by ValidateDocument
+		return []string{"int", "long", "double"}
+	case reflect.Float32, reflect.Float64:
+		return []string{"number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return []string{"binData"}
+		}
+		return []string{"array"}
+	case reflect.Array:
+		return []string{"array"}
+	case reflect.Map, reflect.Struct:
+		return []string{"object"}
+	}
+	return nil
+}
+
+// isIntegerType reports whether a type is an integer type, excluding the driver's
+// integer types like primitive.DateTime
+func isIntegerType(t reflect.Type) bool {
+	if t == nil || isBSONType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isArrayType reports whether values of a type are stored as arrays
+func isArrayType(t reflect.Type) bool {
+	if t == bsonAType {
+		return true
+	}
+	if t == bsonDType || isBSONType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// storedField returns the field of a struct type matching a schema field name, like
+// ValidateDocument matches them, with the name the field is stored under
+func storedField(structType reflect.Type, fieldName string) (reflect.StructField, string, bool) {
+	if structType == nil {
+		return reflect.StructField{}, "", false
+	}
+
+	var folded reflect.StructField
+	foldedName, found := "", false
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		name := strings.Split(structField.Tag.Get("bson"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		storedName := name
+		if name == "" {
+			// The driver stores untagged fields under their lowercased name
+			name, storedName = structField.Name, strings.ToLower(structField.Name)
+		}
+
+		if name == fieldName {
+			return structField, storedName, true
+		}
+		if !found && strings.EqualFold(name, fieldName) {
+			folded, foldedName, found = structField, storedName, true
+		}
+	}
+	return folded, foldedName, found
+}
@@ -0,0 +1,239 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Built-in string formats of Field.Format
+const (
+	FormatEmail    = "email"
+	FormatURL      = "url"
+	FormatUUID     = "uuid"
+	FormatObjectID = "objectid"
+	FormatDate     = "date"
+	FormatDateTime = "datetime"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// patterns caches the compiled Match expressions
+	patterns sync.Map
+)
+
+// validateString checks a string value against the length, pattern and format rules of a field
+func validateString(field Field, val reflect.Value, path string, validationErr *errors.ValidationError) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.String {
+		return
+	}
+	str := val.String()
+
+	length := utf8.RuneCountInString(str)
+	if field.MinLength > 0 && length < field.MinLength {
+		validationErr.Add(path, "minLength", str,
+			fmt.Sprintf("field '%s' length %d is less than minimum length %d", path, length, field.MinLength))
+	}
+	if field.MaxLength > 0 && length > field.MaxLength {
+		validationErr.Add(path, "maxLength", str,
+			fmt.Sprintf("field '%s' length %d is greater than maximum length %d", path, length, field.MaxLength))
+	}
+
+	if field.Match != "" {
+		pattern, err := compilePattern(field.Match)
+		if err != nil {
+			validationErr.Add(path, "match", str, fmt.Sprintf("field '%s' has invalid pattern: %v", path, err))
+		} else if !pattern.MatchString(str) {
+			validationErr.Add(path, "match", str,
+				fmt.Sprintf("field '%s' value does not match pattern '%s'", path, field.Match))
+		}
+	}
+
+	if field.Format != "" {
+		valid, known := matchesFormat(field.Format, str)
+		switch {
+		case !known:
+			validationErr.Add(path, "format", str, fmt.Sprintf("field '%s' has unknown format '%s'", path, field.Format))
+		case !valid:
+			validationErr.Add(path, "format", str, fmt.Sprintf("field '%s' value is not a valid %s", path, field.Format))
+		}
+	}
+}
+
+// compilePattern returns the compiled regular expression of a Match rule
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(expr, pattern)
+	return pattern, nil
+}
+
+// matchesFormat reports whether a string has a built-in format, and whether the format is known
+func matchesFormat(format, str string) (valid bool, known bool) {
+	switch format {
+	case FormatEmail:
+		return emailPattern.MatchString(str), true
+	case FormatURL:
+		u, err := url.ParseRequestURI(str)
+		return err == nil && u.Scheme != "" && u.Host != "", true
+	case FormatUUID:
+		return uuidPattern.MatchString(str), true
+	case FormatObjectID:
+		return primitive.IsValidObjectID(str), true
+	case FormatDate:
+		_, err := time.Parse("2006-01-02", str)
+		return err == nil, true
+	case FormatDateTime:
+		_, err := time.Parse(time.RFC3339, str)
+		return err == nil, true
+	}
+	return false, false
+}
+
+// transformString applies the Trim, Lowercase and Uppercase transforms of a field
+func (f Field) transformString(str string) string {
+	if f.Trim {
+		str = strings.TrimSpace(str)
+	}
+	if f.Lowercase {
+		str = strings.ToLower(str)
+	}
+	if f.Uppercase {
+		str = strings.ToUpper(str)
+	}
+	return str
+}
+
+// hasTransform reports whether a field transforms string values
+func (f Field) hasTransform() bool {
+	return f.Trim || f.Lowercase || f.Uppercase
+}
+
+// ApplyTransforms applies the Trim, Lowercase and Uppercase transforms of the schema
+// fields to the string values of a struct or map document, including the fields of
+// sub-documents with a nested schema
+func (s *Schema) ApplyTransforms(doc interface{}) {
+	s.transformNested(reflect.ValueOf(doc))
+}
+
+// transformNested transforms a document, or each element of an array of documents
+func (s *Schema) transformNested(val reflect.Value) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	switch {
+	case val.Kind() == reflect.Struct:
+		lookup := newFieldLookup(val)
+		for fieldName, field := range s.Fields {
+			if docField, exists := lookup.field(fieldName); exists && docField.CanSet() {
+				field.transformValue(docField, func(v reflect.Value) { docField.Set(v) })
+			}
+		}
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String:
+		for fieldName, field := range s.Fields {
+			key := reflect.ValueOf(fieldName).Convert(val.Type().Key())
+			if value := val.MapIndex(key); value.IsValid() {
+				field.transformValue(value, func(v reflect.Value) { val.SetMapIndex(key, v) })
+			}
+		}
+	case val.Kind() == reflect.Slice || val.Kind() == reflect.Array:
+		if val.Type() == bsonDType {
+			for i := 0; i < val.Len(); i++ {
+				e := val.Index(i)
+				if field, exists := s.Fields[e.Field(0).String()]; exists && e.Field(1).CanSet() {
+					value := e.Field(1)
+					field.transformValue(value, func(v reflect.Value) { value.Set(v) })
+				}
+			}
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			s.transformNested(val.Index(i))
+		}
+	}
+}
+
+// transformValue transforms a string value, storing the result with set when the value
+// cannot be set in place, or the sub-documents of the value when the field has a nested schema
+func (f Field) transformValue(val reflect.Value, set func(reflect.Value)) {
+	if f.Schema != nil {
+		f.Schema.transformNested(val)
+		return
+	}
+	if !f.hasTransform() {
+		return
+	}
+
+	// Unwrap pointers and interface values, like the values of a bson.M
+	str := val
+	for str.Kind() == reflect.Ptr || str.Kind() == reflect.Interface {
+		if str.IsNil() {
+			return
+		}
+		str = str.Elem()
+	}
+	if str.Kind() != reflect.String {
+		return
+	}
+
+	transformed := f.transformString(str.String())
+	if str.CanSet() {
+		str.SetString(transformed)
+		return
+	}
+	set(reflect.ValueOf(transformed).Convert(str.Type()))
+}
+
+// TransformUpdate applies the string transforms of the schema fields to the values an
+// update sets with $set and $setOnInsert, or with plain fields for a replacement
+func (s *Schema) TransformUpdate(update bson.M) {
+	transform := func(values interface{}) {
+		fields, ok := values.(bson.M)
+		if !ok {
+			if m, isMap := values.(map[string]interface{}); isMap {
+				fields = m
+			}
+		}
+		for path, value := range fields {
+			if field, exists := s.fieldAt(path); exists {
+				field.transformValue(reflect.ValueOf(value), func(v reflect.Value) { fields[path] = v.Interface() })
+			}
+		}
+	}
+
+	for key, value := range update {
+		switch {
+		case key == "$set" || key == "$setOnInsert":
+			transform(value)
+		case !strings.HasPrefix(key, "$"):
+			transform(update)
+			return
+		}
+	}
+}
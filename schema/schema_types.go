@@ -0,0 +1,238 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+	bsonDType    = reflect.TypeOf(primitive.D{})
+	bsonAType    = reflect.TypeOf(primitive.A{})
+)
+
+// checkType records a type error when a value is not compatible with the type of
+// a field and reports whether it was compatible
+func checkType(field Field, val reflect.Value, path string, validationErr *errors.ValidationError) bool {
+	if typeMatches(field.Type, val) {
+		return true
+	}
+	for val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+	validationErr.Add(path, "type", val.Interface(),
+		fmt.Sprintf("field '%s' has type %s, expected %s", path, val.Type(), reflect.TypeOf(field.Type)))
+	return false
+}
+
+// typeMatches reports whether a value is compatible with a field's Type, which holds a
+// value of the expected type. Types are compared by kind, so any integer matches an
+// int field, any number a float field, and documents match struct or map fields.
+// A nil Type or a nil value always matches.
+func typeMatches(expected interface{}, val reflect.Value) bool {
+	if expected == nil || !val.IsValid() {
+		return true
+	}
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return true
+		}
+		val = val.Elem()
+	}
+
+	expectedType := reflect.TypeOf(expected)
+	for expectedType.Kind() == reflect.Ptr {
+		expectedType = expectedType.Elem()
+	}
+	if val.Type().AssignableTo(expectedType) {
+		return true
+	}
+
+	// Time values may be stored as BSON dates
+	if expectedType == timeType {
+		return val.Type() == dateTimeType
+	}
+
+	// Other BSON types, like ObjectIDs, must match exactly
+	if isBSONType(expectedType) {
+		return false
+	}
+
+	switch expectedType.Kind() {
+	case reflect.String, reflect.Bool:
+		return val.Kind() == expectedType.Kind()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Whole floats are accepted, as decoded JSON numbers are float64
+		if val.Kind() == reflect.Float32 || val.Kind() == reflect.Float64 {
+			return val.Float() == math.Trunc(val.Float())
+		}
+		return isNumeric(val.Kind())
+	case reflect.Float32, reflect.Float64:
+		return isNumeric(val.Kind())
+	case reflect.Slice, reflect.Array:
+		// Arrays decoded into untyped documents are bson.A values
+		if val.Type() == bsonAType {
+			return true
+		}
+		return (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type() != bsonDType && !isBSONType(val.Type())
+	case reflect.Map, reflect.Struct:
+		return isDocument(val)
+	}
+
+	return false
+}
+
+// isBSONType reports whether a type is defined by the MongoDB driver
+func isBSONType(t reflect.Type) bool {
+	return strings.HasPrefix(t.PkgPath(), "go.mongodb.org/mongo-driver/")
+}
+
+// isDocument reports whether a value is stored as a sub-document
+func isDocument(val reflect.Value) bool {
+	switch {
+	case val.Type() == bsonDType || val.Kind() == reflect.Map:
+		return true
+	case val.Kind() == reflect.Struct:
+		return val.Type() != timeType && !isBSONType(val.Type())
+	}
+	return false
+}
+
+// fieldAt returns the schema field stored at a dotted path, following nested schemas.
+// Array indexes and positional operators in the path, like "items.1.sku" or
+// "items.$.sku", are skipped.
+func (s *Schema) fieldAt(path string) (Field, bool) {
+	segments := strings.Split(path, ".")
+	current := s
+
+	for i := 0; i < len(segments); i++ {
+		field, exists := current.Fields[segments[i]]
+		if !exists {
+			return Field{}, false
+		}
+
+		// Skip array indexes following the field
+		j := i + 1
+		for j < len(segments) && isArraySegment(segments[j]) {
+			j++
+		}
+		if j == len(segments) {
+			// A path ending with an index addresses an array element
+			if j > i+1 {
+				return Field{}, false
+			}
+			return field, true
+		}
+
+		if field.Schema == nil {
+			return Field{}, false
+		}
+		current = field.Schema
+		i = j - 1
+	}
+
+	return Field{}, false
+}
+
+// isArraySegment reports whether a path segment is an array index or positional operator
+func isArraySegment(segment string) bool {
+	if _, err := strconv.Atoi(segment); err == nil {
+		return true
+	}
+	return segment == "$" || strings.HasPrefix(segment, "$[")
+}
+
+// isPositional reports whether a path segment is a positional operator: $, $[] or $[identifier]
+func isPositional(segment string) bool {
+	return segment == "$" || strings.HasPrefix(segment, "$[")
+}
+
+// hasPositional reports whether a dotted path contains a positional operator
+func hasPositional(path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if isPositional(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// elementSchemaAt returns the schema of the sub-documents of the array field a path
+// addresses an element of, like "items.$"
+func (s *Schema) elementSchemaAt(path string) (*Schema, bool) {
+	segments := strings.Split(path, ".")
+	end := len(segments)
+	for end > 0 && isArraySegment(segments[end-1]) {
+		end--
+	}
+	if end == 0 || end == len(segments) {
+		return nil, false
+	}
+	field, exists := s.fieldAt(strings.Join(segments[:end], "."))
+	if !exists || field.Schema == nil {
+		return nil, false
+	}
+	return field.Schema, true
+}
+
+// ValidateUpdate checks the types of the values an update sets with $set and
+// $setOnInsert, or with plain fields for a replacement, against the schema fields
+// they target. Values set through a positional operator, like "items.$.qty", are
+// checked against every rule of their field or element schema, as the elements they
+// update are only known to the server. Failures are reported in an *errors.ValidationError.
+func (s *Schema) ValidateUpdate(update bson.M) error {
+	validationErr := &errors.ValidationError{}
+
+	check := func(values interface{}) {
+		fields, ok := values.(bson.M)
+		if !ok {
+			if m, isMap := values.(map[string]interface{}); isMap {
+				fields = m
+			}
+		}
+		for path, value := range fields {
+			val := reflect.ValueOf(value)
+			if !hasPositional(path) {
+				if field, exists := s.fieldAt(path); exists {
+					checkType(field, val, path, validationErr)
+				}
+				continue
+			}
+
+			if !val.IsValid() {
+				continue
+			}
+			if field, exists := s.fieldAt(path); exists {
+				validateField(field, val, path, validationErr)
+			} else if elementSchema, ok := s.elementSchemaAt(path); ok {
+				elementSchema.validateNested(val, path, validationErr)
+			}
+		}
+	}
+
+	for key, value := range update {
+		switch {
+		case key == "$set" || key == "$setOnInsert":
+			check(value)
+		case !strings.HasPrefix(key, "$"):
+			check(bson.M{key: value})
+		}
+	}
+
+	// Report the failures in a stable order, as fields are visited in map order
+	sort.SliceStable(validationErr.Errors, func(i, j int) bool {
+		return validationErr.Errors[i].Path < validationErr.Errors[j].Path
+	})
+	return validationErr.ErrOrNil()
+}
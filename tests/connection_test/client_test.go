@@ -215,6 +215,24 @@ func TestClient_RegisterModel_GetModel(t *testing.T) {
 	assert.Nil(t, client.GetModel("nonExistentModel"), "Nonexistent model should return nil")
 }
 
+func TestClient_RegisterRefModel_GetRefModel(t *testing.T) {
+	// A client built without the registry initializes it on registration
+	client := &connection.Client{
+		Models: make(map[string]interface{}),
+	}
+
+	type TestModel struct {
+		Name string
+	}
+	model := &TestModel{Name: "TestModel"}
+
+	client.RegisterRefModel("testModel", model)
+
+	assert.Equal(t, model, client.GetRefModel("testModel"), "GetRefModel should return the same model instance")
+	assert.Nil(t, client.GetModel("testModel"), "Reference models should not be registered as models")
+	assert.Nil(t, client.GetRefModel("nonExistentModel"), "Nonexistent model should return nil")
+}
+
 func TestClient_Disconnect_NilClient(t *testing.T) {
 	client := &connection.Client{
 		MongoClient: nil,
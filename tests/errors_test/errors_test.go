@@ -18,6 +18,8 @@ func TestErrors_StandardErrors(t *testing.T) {
 		errors.ErrDatabase,
 		errors.ErrConnection,
 		errors.ErrDecoding,
+		errors.ErrVersionConflict,
+		errors.ErrMigrationLocked,
 	}
 
 	for _, err := range standardErrors {
@@ -112,3 +114,65 @@ func TestNestedWrapping(t *testing.T) {
 		t.Errorf("Nested wrapped error should have a message")
 	}
 }
+
+func TestBatchError(t *testing.T) {
+	batchErr := &errors.BatchError{}
+	if batchErr.ErrOrNil() != nil {
+		t.Error("expected empty batch error to be nil")
+	}
+
+	batchErr.Add(3, errors.WithDetails(errors.ErrValidation, "name is required"))
+	batchErr.Add(7, errors.ErrDatabase)
+
+	err := errors.Wrap(batchErr.ErrOrNil(), "import failed")
+	if !errors.IsValidationError(err) || !errors.IsDatabaseError(err) {
+		t.Errorf("expected batch error to match its item errors, got %v", err)
+	}
+	if errors.IsNotFound(err) {
+		t.Error("expected batch error not to match unrelated errors")
+	}
+
+	found, ok := errors.AsBatchError(err)
+	if !ok {
+		t.Fatal("expected AsBatchError to find the batch error")
+	}
+	if indexes := found.Indexes(); len(indexes) != 2 || indexes[0] != 3 || indexes[1] != 7 {
+		t.Errorf("unexpected indexes %v", indexes)
+	}
+
+	expected := "2 item(s) failed: [3] validation failed: name is required; [7] database operation failed"
+	if batchErr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, batchErr.Error())
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	validationErr := &errors.ValidationError{}
+	if validationErr.ErrOrNil() != nil {
+		t.Error("expected empty validation error to be nil")
+	}
+
+	validationErr.Add("name", "required", nil, "required field 'name' not found in document")
+	validationErr.Add("age", "min", 12, "field 'age' value 12 is less than minimum 18")
+
+	err := errors.Wrap(validationErr.ErrOrNil(), "create failed")
+	if !errors.IsValidationError(err) || !stderrors.Is(err, errors.ErrValidation) {
+		t.Errorf("expected validation error to match ErrValidation, got %v", err)
+	}
+	if errors.IsDatabaseError(err) {
+		t.Error("expected validation error not to match unrelated errors")
+	}
+
+	found, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatal("expected AsValidationError to find the validation error")
+	}
+	if paths := found.Paths(); len(paths) != 2 || paths[0] != "name" || paths[1] != "age" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+
+	expected := "validation failed: required field 'name' not found in document; field 'age' value 12 is less than minimum 18"
+	if validationErr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, validationErr.Error())
+	}
+}
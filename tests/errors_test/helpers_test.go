@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/isimtekin/merhongo/errors"
 	"strings"
@@ -75,6 +76,18 @@ func TestIsErrorFunctions(t *testing.T) {
 			checkFn:  errors.IsDecodingError,
 			expected: true,
 		},
+		{
+			name:     "IsVersionConflict with wrapped ErrVersionConflict",
+			err:      errors.WrapWithID(errors.ErrVersionConflict, "document changed", "123"),
+			checkFn:  errors.IsVersionConflict,
+			expected: true,
+		},
+		{
+			name:     "IsMigrationLocked with wrapped ErrMigrationLocked",
+			err:      errors.WithDetails(errors.ErrMigrationLocked, "held by deploy-1"),
+			checkFn:  errors.IsMigrationLocked,
+			expected: true,
+		},
 		{
 			name:     "IsError with nil error",
 			err:      nil,
@@ -179,10 +192,11 @@ func TestFormatError(t *testing.T) {
 func TestToErrorResponse(t *testing.T) {
 	// Test converting errors to structured responses
 	testCases := []struct {
-		name          string
-		err           error
-		expectedCode  string
-		expectedIsSet bool
+		name            string
+		err             error
+		expectedCode    string
+		expectedIsSet   bool
+		expectedDetails string
 	}{
 		{
 			name:          "nil error",
@@ -238,6 +252,32 @@ func TestToErrorResponse(t *testing.T) {
 			expectedCode:  "decoding_error",
 			expectedIsSet: true,
 		},
+		{
+			name:          "version conflict error",
+			err:           errors.ErrVersionConflict,
+			expectedCode:  "version_conflict",
+			expectedIsSet: true,
+		},
+		{
+			name:          "migration locked error",
+			err:           errors.ErrMigrationLocked,
+			expectedCode:  "migration_locked",
+			expectedIsSet: true,
+		},
+		{
+			name:            "version conflict error with details",
+			err:             errors.WithDetails(errors.ErrVersionConflict, "document was modified"),
+			expectedCode:    "version_conflict",
+			expectedIsSet:   true,
+			expectedDetails: "document was modified",
+		},
+		{
+			name:            "migration locked error with details",
+			err:             errors.WithDetails(errors.ErrMigrationLocked, "held by deploy-1 since 2025-01-01T00:00:00Z"),
+			expectedCode:    "migration_locked",
+			expectedIsSet:   true,
+			expectedDetails: "held by deploy-1 since 2025-01-01T00:00:00Z",
+		},
 		{
 			name:          "unknown error",
 			err:           fmt.Errorf("unknown error"),
@@ -266,6 +306,11 @@ func TestToErrorResponse(t *testing.T) {
 					t.Errorf("Expected details to be extracted from error")
 				}
 			}
+
+			// Details don't repeat the message of the base error
+			if tc.expectedDetails != "" && response.Details != tc.expectedDetails {
+				t.Errorf("Expected details '%s', got '%s'", tc.expectedDetails, response.Details)
+			}
 		})
 	}
 }
@@ -290,3 +335,30 @@ func TestErrorResponseStructure(t *testing.T) {
 		t.Errorf("Expected details to contain the field message")
 	}
 }
+
+func TestErrorResponseValidationFields(t *testing.T) {
+	validationErr := &errors.ValidationError{}
+	validationErr.Add("email", "required", "", "required field 'email' is empty")
+	validationErr.Add("address.zip", "custom", "abc", "field 'address.zip' failed custom validation")
+
+	response := errors.ToErrorResponse(errors.Wrap(validationErr, "create failed"))
+	if response.Code != "validation_error" {
+		t.Errorf("Expected code 'validation_error', got '%s'", response.Code)
+	}
+	if len(response.Fields) != 2 || response.Fields[1].Path != "address.zip" || response.Fields[1].Rule != "custom" {
+		t.Fatalf("Expected the failed fields in the response, got %+v", response.Fields)
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	if !strings.Contains(string(data), `{"path":"address.zip","rule":"custom","value":"abc","message":"field 'address.zip' failed custom validation"}`) {
+		t.Errorf("Expected fields to be rendered in JSON, got %s", data)
+	}
+
+	// Other errors have no field list
+	if fields := errors.ToErrorResponse(errors.ErrNotFound).Fields; fields != nil {
+		t.Errorf("Expected no fields, got %+v", fields)
+	}
+}
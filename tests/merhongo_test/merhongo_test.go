@@ -67,6 +67,28 @@ func TestModelNew_WithGenerics(t *testing.T) {
 	_, _ = userModel.Collection.DeleteMany(context.Background(), map[string]interface{}{})
 }
 
+func TestModelNew_RegistersModel(t *testing.T) {
+	client, err := merhongo.Connect("mongodb://localhost:27017", "merhongo_test_registry")
+	if err != nil {
+		t.Skip("Skipping test; could not connect to MongoDB")
+		return
+	}
+	defer merhongo.Disconnect()
+
+	userModel := merhongo.ModelNew[TestUser]("RegisteredUser", merhongo.SchemaNew(
+		map[string]schema.Field{"Username": {Required: true}},
+		schema.WithCollection("test_users_registry"),
+	))
+
+	// GetModel still returns a pointer to the model type
+	_, ok := client.GetModel("RegisteredUser").(*TestUser)
+	assert.True(t, ok, "GetModel should return a pointer to the model type")
+
+	// The model itself is registered for references
+	assert.Equal(t, userModel, client.GetRefModel("RegisteredUser"), "GetRefModel should return the model")
+	assert.Equal(t, client, userModel.Client, "Model should be bound to its connection")
+}
+
 func TestModelNew_WithOptions(t *testing.T) {
 	// Connect to MongoDB
 	_, err := merhongo.Connect("mongodb://localhost:27017", "merhongo_test_options")
@@ -0,0 +1,311 @@
+package migrate_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/connection"
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/migrate"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// noop is a migration step that changes nothing
+func noop(ctx context.Context, client *connection.Client) error {
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	m := migrate.New(nil)
+
+	err := m.Register(
+		migrate.Migration{Version: 2, Description: "second", Up: noop},
+		migrate.Migration{Version: 1, Description: "first", Up: noop},
+	)
+	testutil.AssertNoError(t, err, "Failed to register migrations")
+
+	migrations := m.Migrations()
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Errorf("Expected migrations ordered by version, got %v", migrations)
+	}
+
+	tests := []struct {
+		name      string
+		migration migrate.Migration
+	}{
+		{"duplicate version", migrate.Migration{Version: 1, Up: noop}},
+		{"zero version", migrate.Migration{Version: 0, Up: noop}},
+		{"negative version", migrate.Migration{Version: -1, Up: noop}},
+		{"missing up step", migrate.Migration{Version: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.Register(tt.migration)
+			testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+		})
+	}
+}
+
+func TestUp_NilCollection(t *testing.T) {
+	m := migrate.New(nil)
+	testutil.AssertNoError(t, m.Register(migrate.Migration{Version: 1, Up: noop}), "Failed to register")
+
+	_, err := m.Up(context.Background())
+	testutil.AssertErrorType(t, err, errors.IsNilCollectionError, "nil collection")
+	_, err = m.Down(context.Background())
+	testutil.AssertErrorType(t, err, errors.IsNilCollectionError, "nil collection")
+	_, err = m.Status(context.Background())
+	testutil.AssertErrorType(t, err, errors.IsNilCollectionError, "nil collection")
+}
+
+// newMigrator returns a migrator recording migrations in a clean collection
+func newMigrator(t *testing.T, opts ...migrate.Options) (*migrate.Migrator, func()) {
+	client, cleanup := testutil.CreateTestClient(t)
+	m := migrate.New(client, opts...)
+	testutil.DropCollection(t, client.Database, m.Collection.Name())
+
+	return m, func() {
+		testutil.DropCollection(t, client.Database, m.Collection.Name())
+		testutil.DropCollection(t, client.Database, "migrated_users")
+		cleanup()
+	}
+}
+
+// renameField returns a migration renaming a field of the users
+func renameField(version int64, from, to string) migrate.Migration {
+	rename := func(from, to string) migrate.Func {
+		return func(ctx context.Context, client *connection.Client) error {
+			_, err := client.Database.Collection("migrated_users").UpdateMany(ctx,
+				bson.M{from: bson.M{"$exists": true}}, bson.M{"$rename": bson.M{from: to}})
+			return err
+		}
+	}
+	return migrate.Migration{
+		Version:     version,
+		Description: "rename " + from + " to " + to,
+		Up:          rename(from, to),
+		Down:        rename(to, from),
+	}
+}
+
+func TestUpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := newMigrator(t)
+	defer cleanup()
+
+	users := m.Client.Database.Collection("migrated_users")
+	_, err := users.InsertOne(ctx, bson.M{"name": "John"})
+	testutil.AssertNoError(t, err, "Failed to insert user")
+
+	err = m.Register(renameField(1, "name", "fullName"), renameField(2, "fullName", "displayName"))
+	testutil.AssertNoError(t, err, "Failed to register migrations")
+
+	// A dry run lists the pending migrations without applying them
+	planned, err := m.Up(ctx, migrate.RunOptions{DryRun: true})
+	testutil.AssertNoError(t, err, "Failed to plan migrations")
+	if len(planned) != 2 {
+		t.Errorf("Expected 2 planned migrations, got %d", len(planned))
+	}
+	statuses, err := m.Status(ctx)
+	testutil.AssertNoError(t, err, "Failed to get status")
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("Expected migration %d to be pending after a dry run", status.Version)
+		}
+	}
+
+	applied, err := m.Up(ctx, migrate.RunOptions{Steps: 1})
+	testutil.AssertNoError(t, err, "Failed to apply the first migration")
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("Expected migration 1 to be applied, got %v", applied)
+	}
+
+	applied, err = m.Up(ctx)
+	testutil.AssertNoError(t, err, "Failed to apply the pending migrations")
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("Expected migration 2 to be applied, got %v", applied)
+	}
+
+	var user bson.M
+	testutil.AssertNoError(t, users.FindOne(ctx, bson.M{}).Decode(&user), "Failed to find user")
+	if user["displayName"] != "John" {
+		t.Errorf("Expected the user to be migrated, got %v", user)
+	}
+
+	statuses, err = m.Status(ctx)
+	testutil.AssertNoError(t, err, "Failed to get status")
+	if len(statuses) != 2 || !statuses[0].Applied || !statuses[1].Applied || statuses[1].AppliedAt.IsZero() {
+		t.Errorf("Expected both migrations to be applied, got %v", statuses)
+	}
+
+	// Nothing is left to apply
+	applied, err = m.Up(ctx)
+	testutil.AssertNoError(t, err, "Failed to run up without pending migrations")
+	if len(applied) != 0 {
+		t.Errorf("Expected no migration to be applied, got %v", applied)
+	}
+
+	reverted, err := m.Down(ctx, migrate.RunOptions{Steps: 2})
+	testutil.AssertNoError(t, err, "Failed to revert migrations")
+	if len(reverted) != 2 || reverted[0].Version != 2 || reverted[1].Version != 1 {
+		t.Errorf("Expected migrations 2 and 1 to be reverted, got %v", reverted)
+	}
+
+	user = nil
+	testutil.AssertNoError(t, users.FindOne(ctx, bson.M{}).Decode(&user), "Failed to find user")
+	if user["name"] != "John" {
+		t.Errorf("Expected the user to be restored, got %v", user)
+	}
+}
+
+func TestUp_FailingMigration(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := newMigrator(t)
+	defer cleanup()
+
+	failure := stderrors.New("boom")
+	err := m.Register(
+		migrate.Migration{Version: 1, Up: noop},
+		migrate.Migration{Version: 2, Up: func(ctx context.Context, client *connection.Client) error {
+			return failure
+		}},
+		migrate.Migration{Version: 3, Up: noop},
+	)
+	testutil.AssertNoError(t, err, "Failed to register migrations")
+
+	applied, err := m.Up(ctx)
+	if !stderrors.Is(err, failure) {
+		t.Errorf("Expected the migration error, got %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("Expected only migration 1 to be applied, got %v", applied)
+	}
+
+	// Migration 1 has no down step, so it can't be reverted
+	_, err = m.Down(ctx, migrate.RunOptions{DryRun: true})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	// The lock is released after a failure
+	count, err := m.Collection.CountDocuments(ctx, bson.M{"_id": "lock"})
+	testutil.AssertNoError(t, err, "Failed to count lock documents")
+	if count != 0 {
+		t.Error("Expected the lock to be released")
+	}
+}
+
+func TestUp_Locked(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := newMigrator(t, migrate.Options{LockTTL: time.Minute})
+	defer cleanup()
+	testutil.AssertNoError(t, m.Register(migrate.Migration{Version: 1, Up: noop}), "Failed to register")
+
+	// Another deploy holds the lock
+	now := time.Now().UTC()
+	_, err := m.Collection.InsertOne(ctx, bson.M{
+		"_id": "lock", "owner": "other", "lockedAt": now, "expiresAt": now.Add(time.Minute),
+	})
+	testutil.AssertNoError(t, err, "Failed to insert lock")
+
+	_, err = m.Up(ctx)
+	testutil.AssertErrorType(t, err, errors.IsMigrationLocked, "migration locked")
+
+	// An expired lock is taken over
+	_, err = m.Collection.UpdateOne(ctx, bson.M{"_id": "lock"},
+		bson.M{"$set": bson.M{"expiresAt": now.Add(-time.Minute)}})
+	testutil.AssertNoError(t, err, "Failed to expire lock")
+
+	applied, err := m.Up(ctx)
+	testutil.AssertNoError(t, err, "Failed to take over an expired lock")
+	if len(applied) != 1 {
+		t.Errorf("Expected migration 1 to be applied, got %v", applied)
+	}
+}
+
+func TestUp_RenewsLock(t *testing.T) {
+	ctx := context.Background()
+	opts := migrate.Options{LockTTL: 300 * time.Millisecond}
+	m, cleanup := newMigrator(t, opts)
+	defer cleanup()
+
+	other := migrate.New(m.Client, opts)
+	testutil.AssertNoError(t, other.Register(migrate.Migration{Version: 1, Up: noop}), "Failed to register")
+
+	// The migration outlives the initial expiry of the lock, which is renewed meanwhile,
+	// so another deploy can't take it over
+	var otherErr error
+	err := m.Register(migrate.Migration{Version: 1, Up: func(ctx context.Context, client *connection.Client) error {
+		time.Sleep(700 * time.Millisecond)
+		_, otherErr = other.Up(ctx)
+		return nil
+	}})
+	testutil.AssertNoError(t, err, "Failed to register migration")
+
+	applied, err := m.Up(ctx)
+	testutil.AssertNoError(t, err, "Failed to apply a migration outliving the lock TTL")
+	if len(applied) != 1 {
+		t.Errorf("Expected migration 1 to be applied, got %v", applied)
+	}
+	testutil.AssertErrorType(t, otherErr, errors.IsMigrationLocked, "migration locked")
+}
+
+func TestUp_LockTakenOver(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := newMigrator(t)
+	defer cleanup()
+
+	// Another deploy takes the lock over while migration 1 runs
+	takeOver := func(ctx context.Context, client *connection.Client) error {
+		now := time.Now().UTC()
+		_, err := m.Collection.ReplaceOne(ctx, bson.M{"_id": "lock"}, bson.M{
+			"owner": "other", "lockedAt": now, "expiresAt": now.Add(time.Minute),
+		})
+		return err
+	}
+	err := m.Register(
+		migrate.Migration{Version: 1, Up: takeOver},
+		migrate.Migration{Version: 2, Up: noop},
+	)
+	testutil.AssertNoError(t, err, "Failed to register migrations")
+
+	applied, err := m.Up(ctx)
+	testutil.AssertErrorType(t, err, errors.IsMigrationLocked, "migration locked")
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("Expected the migrations to stop after migration 1, got %v", applied)
+	}
+
+	// The lock of the other deploy isn't released
+	count, err := m.Collection.CountDocuments(ctx, bson.M{"_id": "lock", "owner": "other"})
+	testutil.AssertNoError(t, err, "Failed to count lock documents")
+	if count != 1 {
+		t.Error("Expected the other deploy to keep the lock")
+	}
+}
+
+func TestUp_Transactional(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := newMigrator(t)
+	defer cleanup()
+
+	// Transactional migrations also run on servers without transactions
+	err := m.Register(migrate.Migration{
+		Version:       1,
+		Transactional: true,
+		Up: func(ctx context.Context, client *connection.Client) error {
+			_, err := client.Database.Collection("migrated_users").InsertOne(ctx, bson.M{"name": "Jane"})
+			return err
+		},
+	})
+	testutil.AssertNoError(t, err, "Failed to register migration")
+
+	_, err = m.Up(ctx)
+	testutil.AssertNoError(t, err, "Failed to apply a transactional migration")
+
+	count, err := m.Client.Database.Collection("migrated_users").CountDocuments(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count users")
+	if count != 1 {
+		t.Errorf("Expected the migration to insert a user, got %d", count)
+	}
+}
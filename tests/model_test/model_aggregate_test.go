@@ -0,0 +1,138 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type roleStats struct {
+	Role   string  `bson:"_id"`
+	Count  int     `bson:"count"`
+	AvgAge float64 `bson:"avgAge"`
+}
+
+func TestAggregate_GroupsDocuments(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_aggregate")
+	defer cleanup()
+
+	users := testutil.CreateTestUsers()
+	for i := range users {
+		testutil.AssertNoError(t, m.Create(ctx, &users[i]), "Failed to create user")
+	}
+
+	pipeline := query.NewPipeline().
+		Match(query.New().Where("active", true)).
+		Group("$role", bson.M{"count": bson.M{"$sum": 1}, "avgAge": bson.M{"$avg": "$age"}}).
+		Sort("_id", true)
+
+	stats, err := model.Aggregate[roleStats](ctx, m.Model, pipeline)
+	testutil.AssertNoError(t, err, "Aggregate failed")
+
+	if len(stats) != 2 || stats[0].Role != "admin" || stats[1].Role != "user" {
+		t.Fatalf("expected admin and user stats, got %+v", stats)
+	}
+	testutil.AssertEqual(t, 1, stats[0].Count, "One active admin")
+	testutil.AssertEqual(t, 2, stats[1].Count, "Two active users")
+	testutil.AssertEqual(t, 26.0, stats[1].AvgAge, "Average age of active users")
+
+	// Counting into a raw document
+	var counts []bson.M
+	err = m.Aggregate(ctx, query.NewPipeline().Count("total"), &counts)
+	testutil.AssertNoError(t, err, "Aggregate failed")
+	if len(counts) != 1 || counts[0]["total"] != int32(len(users)) {
+		t.Errorf("expected a total of %d, got %v", len(users), counts)
+	}
+
+	// Pipeline errors are returned before running the aggregation
+	_, err = model.Aggregate[roleStats](ctx, m.Model, query.NewPipeline().Limit(0))
+	if err == nil {
+		t.Error("expected an error for an invalid pipeline")
+	}
+}
+
+func TestAggregate_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	m, users, cleanup := setupSoftDeleteModel(t, "users_aggregate_soft_delete")
+	defer cleanup()
+
+	testutil.AssertNoError(t, m.DeleteById(ctx, users[0].ID.Hex()), "DeleteById failed")
+
+	count := func(pipeline *query.Pipeline) int32 {
+		var results []bson.M
+		testutil.AssertNoError(t, m.Aggregate(ctx, pipeline.Count("n"), &results), "Aggregate failed")
+		if len(results) == 0 {
+			return 0
+		}
+		return results[0]["n"].(int32)
+	}
+
+	testutil.AssertEqual(t, int32(2), count(query.NewPipeline()), "Soft-deleted documents are excluded")
+	testutil.AssertEqual(t, int32(3), count(query.NewPipeline().WithDeleted()), "WithDeleted includes them")
+	testutil.AssertEqual(t, int32(1), count(query.NewPipeline().Match(query.New().OnlyDeleted())), "OnlyDeleted matches them")
+}
+
+type Place struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	Location  bson.M             `bson:"location"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty"`
+}
+
+func TestAggregate_FirstStages(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "places_aggregate"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{
+		"name": {Type: "", Required: true},
+	}, schema.WithCollection(collName), schema.WithSoftDelete(), schema.WithIndex(schema.Index{
+		Keys: bson.D{{Key: "location", Value: schema.Index2DSphere}},
+	}))
+	m := model.NewGeneric[Place]("Place", s, client.Database)
+
+	point := func(lng, lat float64) bson.M {
+		return bson.M{"type": "Point", "coordinates": bson.A{lng, lat}}
+	}
+	places := []Place{
+		{Name: "near", Location: point(0, 0)},
+		{Name: "far", Location: point(1, 1)},
+		{Name: "closed", Location: point(0, 0.1)},
+	}
+	for i := range places {
+		testutil.AssertNoError(t, m.Create(ctx, &places[i]), "Failed to create place")
+	}
+	testutil.AssertNoError(t, m.DeleteById(ctx, places[2].ID.Hex()), "DeleteById failed")
+
+	// $geoNear must be the first stage; the soft-delete filter is merged into its query
+	geoNear := query.NewPipeline().Stage("$geoNear", bson.M{
+		"near":          point(0, 0),
+		"distanceField": "distance",
+		"query":         bson.M{"name": bson.M{"$ne": "far"}},
+	})
+	var nearby []bson.M
+	testutil.AssertNoError(t, m.Aggregate(ctx, geoNear, &nearby), "Aggregate with $geoNear failed")
+	if len(nearby) != 1 || nearby[0]["name"] != "near" {
+		t.Errorf("Expected only the place that is neither far nor deleted, got %v", nearby)
+	}
+
+	// Stages that don't read documents are left unscoped
+	var indexes []bson.M
+	err := m.Aggregate(ctx, query.NewPipeline().Stage("$indexStats", bson.M{}), &indexes)
+	testutil.AssertNoError(t, err, "Aggregate with $indexStats failed")
+	if len(indexes) < 2 {
+		t.Errorf("Expected the _id and location indexes, got %v", indexes)
+	}
+}
@@ -249,6 +249,39 @@ func TestGenericModel_Create_WithValidationError(t *testing.T) {
 	}
 }
 
+func TestModel_Create_MapDefaults(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "map_defaults"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{
+		"name": {Required: true},
+		"role": {Required: true, Default: "member"},
+	}, schema.WithCollection(collName))
+	m := model.New("MapDefault", s, client.Database)
+
+	// The default is set before the required field is validated
+	doc := bson.M{"name": "Alice"}
+	if err := m.Create(ctx, doc); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if doc["role"] != "member" {
+		t.Errorf("Expected the default to be set on the map, got %v", doc["role"])
+	}
+
+	var stored bson.M
+	if err := m.FindOne(ctx, bson.M{"name": "Alice"}, &stored); err != nil {
+		t.Fatalf("FindOne failed: %v", err)
+	}
+	if stored["role"] != "member" {
+		t.Errorf("Expected the default to be stored, got %v", stored["role"])
+	}
+}
+
 func TestGenericModel_Find_InvalidCollection(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,260 @@
+package model_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func setupBulkModel(t *testing.T, collName string) (*model.GenericModel[testutil.TestUser], func()) {
+	client, cleanup := testutil.CreateTestClient(t)
+	testutil.DropCollection(t, client.Database, collName)
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", testutil.CreateTestSchema(collName), client.Database)
+	return m, func() {
+		testutil.DropCollection(t, client.Database, collName)
+		cleanup()
+	}
+}
+
+func TestCreateMany_SetsIDsAndTimestamps(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_create_many")
+	defer cleanup()
+
+	saved := 0
+	m.Schema.Pre("save", func(doc interface{}) error {
+		saved++
+		return nil
+	})
+
+	var users []*testutil.TestUser
+	for _, user := range testutil.CreateTestUsers() {
+		userCopy := user
+		users = append(users, &userCopy)
+	}
+
+	testutil.AssertNoError(t, m.CreateMany(ctx, users), "Failed to create users")
+	testutil.AssertEqual(t, len(users), saved, "Pre-save middleware should run for every document")
+
+	for i, user := range users {
+		if user.ID.IsZero() || user.CreatedAt.IsZero() {
+			t.Errorf("expected user %d to have an ID and timestamps, got %+v", i, user)
+		}
+		stored, err := m.FindById(ctx, user.ID.Hex())
+		testutil.AssertNoError(t, err, "Failed to find created user")
+		testutil.AssertEqual(t, user.Username, stored.Username, "Stored user should match")
+	}
+}
+
+func TestCreateMany_ReportsFailedIndexes(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_create_many_errors")
+	defer cleanup()
+
+	newUsers := func() []*testutil.TestUser {
+		return []*testutil.TestUser{
+			{Username: "first", Email: "first@example.com", Age: 20},
+			{Username: "", Email: "invalid@example.com", Age: 20}, // missing username
+			{Username: "third", Email: "third@example.com", Age: 20},
+		}
+	}
+
+	// Ordered mode stops at the first failure
+	users := newUsers()
+	err := m.CreateMany(ctx, users)
+	batchErr, ok := errors.AsBatchError(err)
+	if !ok {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+	if !reflect.DeepEqual(batchErr.Indexes(), []int{1}) {
+		t.Errorf("expected index 1 to fail, got %v", batchErr.Indexes())
+	}
+	if !errors.IsValidationError(err) {
+		t.Errorf("expected the batch error to match ErrValidation, got %v", err)
+	}
+	if users[0].ID.IsZero() || !users[2].ID.IsZero() {
+		t.Errorf("expected only the first user to be inserted, got %+v", users)
+	}
+
+	// Unordered mode inserts every valid document; "first" now fails as a duplicate
+	users = newUsers()
+	err = m.CreateMany(ctx, users, model.CreateManyOptions{Unordered: true})
+	batchErr, ok = errors.AsBatchError(err)
+	if !ok {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+	if !reflect.DeepEqual(batchErr.Indexes(), []int{0, 1}) {
+		t.Errorf("expected indexes 0 and 1 to fail, got %v", batchErr.Indexes())
+	}
+	if !users[0].ID.IsZero() || users[2].ID.IsZero() {
+		t.Errorf("expected only the third user to be inserted, got %+v", users)
+	}
+
+	count, err := m.Count(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count users")
+	testutil.AssertEqual(t, int64(2), count, "Two users should be stored")
+}
+
+func TestBulkWrite_MixedOperations(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_bulk_write")
+	defer cleanup()
+
+	existing := testutil.CreateTestUsers()
+	for i := range existing {
+		testutil.AssertNoError(t, m.Create(ctx, &existing[i]), "Failed to create user")
+	}
+
+	updated := 0
+	m.Schema.Post("update", func(doc interface{}) error {
+		updated++
+		return nil
+	})
+
+	newUser := &testutil.TestUser{Username: "new_user", Email: "new@example.com", Age: 25}
+	result, err := m.BulkWrite(ctx, []model.BulkOperation{
+		model.InsertOperation{Document: newUser},
+		model.UpdateOneOperation{Filter: bson.M{"username": "john_doe"}, Update: query.Update().Inc("age", 1)},
+		model.UpdateManyOperation{Filter: bson.M{"role": "user"}, Update: bson.M{"active": false}},
+		model.ReplaceOneOperation{
+			Filter:      bson.M{"username": "jane_smith"},
+			Replacement: testutil.TestUser{Username: "jane_doe", Email: "jane@example.com", Age: 41},
+		},
+		model.UpdateOneOperation{
+			Filter: bson.M{"username": "upserted"},
+			Update: bson.M{"email": "upserted@example.com", "age": 50},
+			Upsert: true,
+		},
+		model.DeleteOneOperation{Filter: bson.M{"username": "missing"}},
+		model.DeleteManyOperation{Filter: bson.M{"username": "alice_wonder"}},
+	})
+	testutil.AssertNoError(t, err, "BulkWrite should succeed")
+
+	testutil.AssertEqual(t, int64(1), result.InsertedCount, "One document should be inserted")
+	testutil.AssertEqual(t, int64(1), result.UpsertedCount, "One document should be upserted")
+	if _, ok := result.UpsertedIDs[4]; !ok {
+		t.Errorf("expected operation 4 to report its upserted ID, got %v", result.UpsertedIDs)
+	}
+	testutil.AssertEqual(t, int64(1), result.DeletedCount, "One document should be deleted")
+	if newUser.ID.IsZero() || newUser.CreatedAt.IsZero() {
+		t.Errorf("expected inserted user to have an ID and timestamps, got %+v", newUser)
+	}
+	testutil.AssertEqual(t, 4, updated, "Post-update middleware should run for every update and replace")
+
+	john, err := m.FindOne(ctx, bson.M{"username": "john_doe"})
+	testutil.AssertNoError(t, err, "Failed to find updated user")
+	testutil.AssertEqual(t, 31, john.Age, "Age should be incremented")
+
+	jane, err := m.FindOne(ctx, bson.M{"username": "jane_doe"})
+	testutil.AssertNoError(t, err, "Failed to find replaced user")
+	if !jane.CreatedAt.Equal(existing[1].CreatedAt.Truncate(time.Millisecond)) {
+		t.Errorf("expected replaced user to keep its createdAt, got %v", jane.CreatedAt)
+	}
+}
+
+func TestBulkWrite_ReportsFailedOperations(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_bulk_write_errors")
+	defer cleanup()
+
+	user := testutil.TestUser{Username: "adult", Email: "adult@example.com", Age: 30}
+	testutil.AssertNoError(t, m.Create(ctx, &user), "Failed to create user")
+
+	ops := []model.BulkOperation{
+		model.UpdateOneOperation{Filter: bson.M{"_id": user.ID}, Update: bson.M{"age": 10}}, // below minimum
+		model.InsertOperation{Document: &testutil.TestUser{Username: "other", Email: "other@example.com", Age: 20}},
+	}
+
+	// Ordered mode stops at the first failure
+	result, err := m.BulkWrite(ctx, ops)
+	batchErr, ok := errors.AsBatchError(err)
+	if !ok {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+	if !reflect.DeepEqual(batchErr.Indexes(), []int{0}) || !errors.IsValidationError(err) {
+		t.Errorf("expected operation 0 to fail validation, got %v", err)
+	}
+	testutil.AssertEqual(t, int64(0), result.InsertedCount, "Nothing should be inserted")
+
+	// Unordered mode applies the remaining operations
+	result, err = m.BulkWrite(ctx, ops, model.BulkWriteOptions{Unordered: true})
+	if batchErr, ok := errors.AsBatchError(err); !ok || !reflect.DeepEqual(batchErr.Indexes(), []int{0}) {
+		t.Errorf("expected operation 0 to fail, got %v", err)
+	}
+	testutil.AssertEqual(t, int64(1), result.InsertedCount, "The valid insert should be applied")
+}
+
+func TestBulkWrite_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	m, users, cleanup := setupSoftDeleteModel(t, "users_bulk_write_soft_delete")
+	defer cleanup()
+
+	result, err := m.BulkWrite(ctx, []model.BulkOperation{
+		model.DeleteOneOperation{Filter: bson.M{"_id": users[0].ID}},
+		model.DeleteManyOperation{Filter: bson.M{"role": "user"}},
+		model.UpdateOneOperation{Filter: bson.M{"username": "bob"}, Update: bson.M{"role": "admin"}},
+	})
+	testutil.AssertNoError(t, err, "BulkWrite should succeed")
+	testutil.AssertEqual(t, int64(3), result.DeletedCount, "Soft deletes should be counted as deletes")
+	testutil.AssertEqual(t, int64(0), result.MatchedCount, "Deleted documents should not be updated")
+
+	count, err := m.Count(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count users")
+	testutil.AssertEqual(t, int64(0), count, "All users should be hidden")
+}
+
+func TestBulkWrite_RejectsSecondWriteOfDocument(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupBulkModel(t, "users_bulk_write_same_document")
+	defer cleanup()
+
+	user := testutil.TestUser{Username: "adult", Email: "adult@example.com", Age: 30}
+	testutil.AssertNoError(t, m.Create(ctx, &user), "Failed to create user")
+
+	// Each update is valid on its own, but together they go below the minimum age
+	ops := []model.BulkOperation{
+		model.UpdateOneOperation{Filter: bson.M{"_id": user.ID}, Update: bson.M{"$inc": bson.M{"age": -7}}},
+		model.UpdateOneOperation{Filter: bson.M{"username": "adult"}, Update: bson.M{"$inc": bson.M{"age": -7}}},
+	}
+
+	result, err := m.BulkWrite(ctx, ops, model.BulkWriteOptions{Unordered: true})
+	batchErr, ok := errors.AsBatchError(err)
+	if !ok || !reflect.DeepEqual(batchErr.Indexes(), []int{1}) || !errors.IsValidationError(err) {
+		t.Fatalf("expected operation 1 to be rejected, got %v", err)
+	}
+	testutil.AssertEqual(t, int64(1), result.MatchedCount, "Only the first update should be applied")
+
+	stored, err := m.FindById(ctx, user.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find user")
+	testutil.AssertEqual(t, 23, stored.Age, "Only the first update should be applied")
+}
+
+func TestBulkWrite_SoftDeleteCountsWrittenDocuments(t *testing.T) {
+	ctx := context.Background()
+	m, users, cleanup := setupSoftDeleteModel(t, "users_bulk_write_soft_delete_counts")
+	defer cleanup()
+
+	// Carol is deleted by another writer after the soft delete was prepared
+	m.Schema.PreHook("update", func(hc *schema.HookContext) error {
+		_, err := m.Collection.DeleteOne(hc.Ctx, bson.M{"_id": users[2].ID})
+		return err
+	})
+
+	result, err := m.BulkWrite(ctx, []model.BulkOperation{
+		model.DeleteManyOperation{Filter: bson.M{"role": "user"}},
+		model.UpdateOneOperation{Filter: bson.M{"_id": users[0].ID}, Update: bson.M{"role": "owner"}},
+	})
+	testutil.AssertNoError(t, err, "BulkWrite should succeed")
+	testutil.AssertEqual(t, int64(1), result.DeletedCount, "Only the document still stored should be counted as deleted")
+	testutil.AssertEqual(t, int64(1), result.MatchedCount, "The update should be counted")
+	testutil.AssertEqual(t, int64(1), result.ModifiedCount, "The update should be counted")
+}
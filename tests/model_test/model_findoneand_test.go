@@ -0,0 +1,81 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func setupFindOneAndModel(t *testing.T, collName string) (*model.GenericModel[testutil.TestUser], func()) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	testutil.DropCollection(t, client.Database, collName)
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", testutil.CreateTestSchema(collName), client.Database)
+	for _, user := range testutil.CreateTestUsers() {
+		userCopy := user
+		if err := m.Create(ctx, &userCopy); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	return m, func() {
+		testutil.DropCollection(t, client.Database, collName)
+		cleanup()
+	}
+}
+
+func TestFindOneAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupFindOneAndModel(t, "users_find_one_and_update")
+	defer cleanup()
+
+	// Claim the youngest user, returning the updated document
+	claimed, err := m.FindOneAndUpdate(ctx, bson.M{"role": "user"}, query.Update().Set("role", "claimed"),
+		model.FindOneAndOptions{Sort: bson.D{{Key: "age", Value: 1}}})
+	testutil.AssertNoError(t, err, "Failed to claim user")
+	testutil.AssertEqual(t, "claimed", claimed.Role, "Returned document should be updated")
+
+	// ReturnBefore returns the document as it was
+	before, err := m.FindOneAndUpdate(ctx, bson.M{"_id": claimed.ID}, bson.M{"$inc": bson.M{"age": 1}},
+		model.FindOneAndOptions{ReturnBefore: true})
+	testutil.AssertNoError(t, err, "Failed to update user")
+	testutil.AssertEqual(t, claimed.Age, before.Age, "Returned document should be the original")
+
+	// Validation runs before the write
+	_, err = m.FindOneAndUpdate(ctx, bson.M{"_id": claimed.ID}, bson.M{"age": 5})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	_, err = m.FindOneAndUpdate(ctx, bson.M{"role": "nobody"}, bson.M{"age": 40})
+	testutil.AssertErrorType(t, err, errors.IsNotFound, "not found")
+}
+
+func TestFindOneAndReplaceAndDelete(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupFindOneAndModel(t, "users_find_one_and_replace")
+	defer cleanup()
+
+	original, err := m.FindOne(ctx, bson.M{"username": "john_doe"})
+	testutil.AssertNoError(t, err, "Failed to find user")
+
+	replaced, err := m.FindOneAndReplace(ctx, bson.M{"_id": original.ID}, &testutil.TestUser{
+		Username: "john_doe", Email: "john.new@example.com", Age: 31, Role: "user",
+	})
+	testutil.AssertNoError(t, err, "Failed to replace user")
+	testutil.AssertEqual(t, "john.new@example.com", replaced.Email, "Returned document should be replaced")
+	if !replaced.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("expected createdAt to be kept, got %v and %v", original.CreatedAt, replaced.CreatedAt)
+	}
+
+	deleted, err := m.FindOneAndDelete(ctx, bson.M{"_id": original.ID})
+	testutil.AssertNoError(t, err, "Failed to delete user")
+	testutil.AssertEqual(t, "john.new@example.com", deleted.Email, "Returned document should be the deleted one")
+
+	_, err = m.FindOneAndDelete(ctx, bson.M{"_id": original.ID})
+	testutil.AssertErrorType(t, err, errors.IsNotFound, "not found")
+}
@@ -0,0 +1,137 @@
+package model_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IndexedSession struct {
+	Token     string    `bson:"token" schema:"unique"`
+	UserID    string    `bson:"userId" schema:"compound=user_created"`
+	CreatedAt time.Time `bson:"createdAt" schema:"compound=user_created:-1"`
+	ExpiresAt time.Time `bson:"expiresAt" schema:"ttl=3600"`
+	Bio       string    `bson:"bio" schema:"index=text"`
+}
+
+func TestNew_CreatesDeclaredIndexes(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := prepareCleanDatabase(t, "indexed_sessions")
+	defer cleanup()
+	defer testutil.DropCollection(t, db, "indexed_sessions")
+
+	s := schema.GenerateFromStruct(IndexedSession{}, schema.WithCollection("indexed_sessions"),
+		schema.WithIndex(schema.Index{
+			Name:          "active_users",
+			Keys:          bson.D{{Key: "userId", Value: 1}},
+			PartialFilter: bson.M{"active": true},
+		}))
+	m := model.New("IndexedSession", s, db)
+
+	cursor, err := m.Collection.Indexes().List(ctx)
+	testutil.AssertNoError(t, err, "Failed to list indexes")
+	var indexes []bson.M
+	testutil.AssertNoError(t, cursor.All(ctx, &indexes), "Failed to decode indexes")
+
+	byName := make(map[string]bson.M)
+	for _, index := range indexes {
+		byName[index["name"].(string)] = index
+	}
+	for _, name := range []string{"token_1", "user_created", "expiresAt_1", "active_users"} {
+		if _, exists := byName[name]; !exists {
+			t.Errorf("Expected index %s, got %v", name, indexes)
+		}
+	}
+	if byName["token_1"]["unique"] != true {
+		t.Errorf("Expected token index to be unique, got %v", byName["token_1"])
+	}
+	if byName["expiresAt_1"]["expireAfterSeconds"] == nil {
+		t.Errorf("Expected TTL index, got %v", byName["expiresAt_1"])
+	}
+	if byName["active_users"]["partialFilterExpression"] == nil {
+		t.Errorf("Expected partial index, got %v", byName["active_users"])
+	}
+	if _, exists := byName["bio_text"]; !exists {
+		t.Errorf("Expected text index, got %v", indexes)
+	}
+}
+
+func TestSyncIndexes(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := prepareCleanDatabase(t, "synced_indexes")
+	defer cleanup()
+	defer testutil.DropCollection(t, db, "synced_indexes")
+
+	// A model created without indexes leaves the collection untouched
+	s := schema.New(map[string]schema.Field{
+		"email":    {Unique: true},
+		"username": {Index: true},
+	}, schema.WithCollection("synced_indexes"),
+		schema.WithIndex(schema.Index{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}}))
+	m := model.New("SyncedIndexes", s, db, model.Options{SkipIndexes: true})
+
+	_, err := m.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "legacy", Value: 1}}})
+	testutil.AssertNoError(t, err, "Failed to create legacy index")
+	_, err = m.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetSparse(true)})
+	testutil.AssertNoError(t, err, "Failed to create sparse username index")
+
+	// A dry run reports the plan without changing anything
+	diff, err := m.SyncIndexes(ctx, model.SyncIndexesOptions{DropStale: true, DryRun: true})
+	testutil.AssertNoError(t, err, "Dry run should succeed")
+	if !reflect.DeepEqual(diff.Created, []string{"email_1", "userId_1_createdAt_-1"}) ||
+		!reflect.DeepEqual(diff.Changed, []string{"username_1"}) ||
+		!reflect.DeepEqual(diff.Stale, []string{"legacy_1"}) {
+		t.Fatalf("Unexpected plan:\n%s", diff)
+	}
+	if names := indexNames(t, m); len(names) != 3 {
+		t.Fatalf("Expected the dry run to leave the indexes, got %v", names)
+	}
+
+	// Without DropStale, only the missing indexes are created
+	_, err = m.SyncIndexes(ctx)
+	testutil.AssertNoError(t, err, "Sync should succeed")
+	if names := indexNames(t, m); len(names) != 5 {
+		t.Fatalf("Expected the missing indexes to be created, got %v", names)
+	}
+
+	// DropStale removes the legacy index and recreates the changed one
+	diff, err = m.SyncIndexes(ctx, model.SyncIndexesOptions{DropStale: true})
+	testutil.AssertNoError(t, err, "Sync with DropStale should succeed")
+	if len(diff.Created) != 0 || len(diff.Changed) != 1 || len(diff.Stale) != 1 {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+	expected := []string{"_id_", "email_1", "userId_1_createdAt_-1", "username_1"}
+	if names := indexNames(t, m); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected indexes %v, got %v", expected, names)
+	}
+
+	diff, err = m.SyncIndexes(ctx)
+	testutil.AssertNoError(t, err, "Sync should succeed")
+	if diff.HasChanges() || len(diff.Unchanged) != 3 {
+		t.Errorf("Expected the indexes to be in sync, got:\n%s", diff)
+	}
+}
+
+// indexNames returns the sorted names of the indexes of a model's collection
+func indexNames(t *testing.T, m *model.Model) []string {
+	cursor, err := m.Collection.Indexes().List(context.Background())
+	testutil.AssertNoError(t, err, "Failed to list indexes")
+	var indexes []bson.M
+	testutil.AssertNoError(t, cursor.All(context.Background(), &indexes), "Failed to decode indexes")
+
+	names := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		names = append(names, index["name"].(string))
+	}
+	sort.Strings(names)
+	return names
+}
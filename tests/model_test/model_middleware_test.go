@@ -0,0 +1,259 @@
+package model_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestModel_PostMiddlewares(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_post_middleware"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+
+	events := map[string][]interface{}{}
+	for _, event := range []string{"save", "update", "delete", "find"} {
+		event := event
+		s.Post(event, func(payload interface{}) error {
+			events[event] = append(events[event], payload)
+			return nil
+		})
+	}
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+
+	user := &testutil.TestUser{Username: "post_user", Email: "post@example.com", Age: 30}
+	if err := m.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if len(events["save"]) != 1 || events["save"][0] != user {
+		t.Errorf("expected post-save middleware to receive the created document, got %v", events["save"])
+	}
+
+	if _, err := m.FindById(ctx, user.ID.Hex()); err != nil {
+		t.Fatalf("FindById failed: %v", err)
+	}
+	if _, err := m.FindWithQuery(ctx, query.New().Where("username", "post_user")); err != nil {
+		t.Fatalf("FindWithQuery failed: %v", err)
+	}
+	if len(events["find"]) != 2 {
+		t.Errorf("expected 2 post-find middleware calls, got %d", len(events["find"]))
+	}
+
+	if err := m.UpdateById(ctx, user.ID.Hex(), bson.M{"age": 31}); err != nil {
+		t.Fatalf("UpdateById failed: %v", err)
+	}
+	if len(events["update"]) != 1 {
+		t.Fatalf("expected 1 post-update middleware call, got %d", len(events["update"]))
+	}
+	updated, ok := events["update"][0].(*testutil.TestUser)
+	if !ok || updated.Age != 31 {
+		t.Errorf("expected post-update middleware to receive the updated document, got %v", events["update"][0])
+	}
+
+	if _, err := m.UpdateWithQuery(ctx, query.New().Where("username", "post_user"), bson.M{"role": "admin"}); err != nil {
+		t.Fatalf("UpdateWithQuery failed: %v", err)
+	}
+	if _, ok := events["update"][1].(*mongo.UpdateResult); !ok {
+		t.Errorf("expected post-update middleware to receive *mongo.UpdateResult, got %T", events["update"][1])
+	}
+
+	if err := m.DeleteById(ctx, user.ID.Hex()); err != nil {
+		t.Fatalf("DeleteById failed: %v", err)
+	}
+	deleted, ok := events["delete"][0].(*testutil.TestUser)
+	if !ok || deleted.Username != "post_user" {
+		t.Errorf("expected post-delete middleware to receive the deleted document, got %v", events["delete"][0])
+	}
+}
+
+func TestModel_PostMiddlewareError(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_post_middleware_error"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{}, schema.WithCollection(collName))
+	s.Post("save", func(doc interface{}) error {
+		return fmt.Errorf("post hook failed")
+	})
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+
+	user := &testutil.TestUser{Username: "post_error", Email: "post_error@example.com"}
+	err := m.Create(ctx, user)
+	if !errors.IsMiddlewareError(err) {
+		t.Errorf("expected middleware error, got %v", err)
+	}
+
+	// The document was written before the post hook ran
+	count, err := m.Count(ctx, bson.M{"username": "post_error"})
+	if err != nil || count != 1 {
+		t.Errorf("expected document to be persisted, count=%d err=%v", count, err)
+	}
+}
+
+func TestModel_PreHooksScopeFilterAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_pre_hooks"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+
+	var operations []string
+	scopeToUsers := func(hc *schema.HookContext) error {
+		operations = append(operations, hc.Operation)
+		if hc.Model != "TestUser" {
+			t.Errorf("expected model name TestUser, got %s", hc.Model)
+		}
+		hc.Filter["role"] = "user"
+		return nil
+	}
+	s.PreHook("find", scopeToUsers)
+	s.PreHook("count", scopeToUsers)
+	s.PreHook("update", func(hc *schema.HookContext) error {
+		hc.Update["$set"].(bson.M)["active"] = false
+		return nil
+	})
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+	for _, user := range testutil.CreateTestUsers() {
+		userCopy := user
+		if err := m.Create(ctx, &userCopy); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	users, err := m.Find(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(users) != 3 {
+		t.Errorf("expected hook to scope Find to 3 users, got %d", len(users))
+	}
+
+	count, err := m.CountWithQuery(ctx, query.New())
+	if err != nil || count != 3 {
+		t.Errorf("expected hook to scope CountWithQuery to 3 users, got %d (err=%v)", count, err)
+	}
+
+	if len(operations) != 2 || operations[0] != "Find" || operations[1] != "CountWithQuery" {
+		t.Errorf("unexpected hook operations: %v", operations)
+	}
+
+	if err := m.UpdateById(ctx, users[0].ID.Hex(), bson.M{"age": 40}); err != nil {
+		t.Fatalf("UpdateById failed: %v", err)
+	}
+
+	var updated testutil.TestUser
+	if err := m.Collection.FindOne(ctx, bson.M{"_id": users[0].ID}).Decode(&updated); err != nil {
+		t.Fatalf("failed to read updated user: %v", err)
+	}
+	if updated.Age != 40 || updated.Active {
+		t.Errorf("expected hook to add active=false to the update, got %+v", updated)
+	}
+}
+
+func TestModel_PreUpdateAndDeleteMiddlewares(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_pre_update_delete"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+
+	// Forbid deleting admin users
+	s.Pre("delete", func(doc interface{}) error {
+		if user, ok := doc.(*testutil.TestUser); ok && user.Role == "admin" {
+			return fmt.Errorf("admin users cannot be deleted")
+		}
+		return nil
+	})
+
+	// Forbid promoting users to admin
+	s.Pre("update", func(doc interface{}) error {
+		if user, ok := doc.(*testutil.TestUser); ok && user.Role == "admin" {
+			return fmt.Errorf("users cannot be promoted to admin")
+		}
+		return nil
+	})
+
+	// Never delete inactive users in bulk
+	var deleteManyFilter bson.M
+	s.Pre("deleteMany", func(filter interface{}) error {
+		deleteManyFilter = filter.(bson.M)
+		deleteManyFilter["active"] = true
+		return nil
+	})
+
+	validated := 0
+	s.Pre("validate", func(doc interface{}) error {
+		validated++
+		return nil
+	})
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+
+	users := testutil.CreateTestUsers()
+	for i := range users {
+		if err := m.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	if validated != len(users) {
+		t.Errorf("expected pre-validate middleware to run %d times, got %d", len(users), validated)
+	}
+
+	admin := users[1]
+	err := m.DeleteById(ctx, admin.ID.Hex())
+	if !errors.IsMiddlewareError(err) {
+		t.Errorf("expected middleware error when deleting admin, got %v", err)
+	}
+
+	err = m.UpdateById(ctx, users[0].ID.Hex(), bson.M{"role": "admin"})
+	if !errors.IsMiddlewareError(err) {
+		t.Errorf("expected middleware error when promoting to admin, got %v", err)
+	}
+
+	if err := m.UpdateById(ctx, users[0].ID.Hex(), bson.M{"age": 35}); err != nil {
+		t.Errorf("expected regular update to succeed, got %v", err)
+	}
+
+	// bob_jones is inactive, so the middleware keeps him
+	deleted, err := m.DeleteWithQuery(ctx, query.New().Where("role", "user"))
+	if err != nil {
+		t.Fatalf("DeleteWithQuery failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 active users to be deleted, got %d", deleted)
+	}
+	if deleteManyFilter["role"] != "user" {
+		t.Errorf("expected pre-deleteMany middleware to receive the filter, got %v", deleteManyFilter)
+	}
+}
@@ -0,0 +1,95 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PopulateCustomer struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty"`
+	Name string             `bson:"name" schema:"required"`
+}
+
+type PopulateOrder struct {
+	ID         primitive.ObjectID   `bson:"_id,omitempty"`
+	Number     string               `bson:"number"`
+	CustomerID primitive.ObjectID   `bson:"customerId" schema:"ref=Customer"`
+	ContactIDs []primitive.ObjectID `bson:"contactIds" schema:"ref=Customer"`
+	Customer   *PopulateCustomer    `bson:"-" schema:"populate=customerId"`
+	Contacts   []PopulateCustomer   `bson:"-" schema:"populate=contactIds"`
+}
+
+func TestPopulate_ResolvesReferences(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+	testutil.DropCollection(t, client.Database, "populate_customers")
+	testutil.DropCollection(t, client.Database, "populate_orders")
+
+	customers := model.NewGeneric[PopulateCustomer]("Customer",
+		schema.GenerateFromStruct(PopulateCustomer{}, schema.WithCollection("populate_customers")), client.Database)
+	orders := model.NewGeneric[PopulateOrder]("Order",
+		schema.GenerateFromStruct(PopulateOrder{}, schema.WithCollection("populate_orders")), client.Database)
+	client.RegisterRefModel("Customer", customers)
+	orders.Client = client
+
+	// Count the queries made against the referenced model
+	lookups := 0
+	customers.Schema.PreHook("find", func(hc *schema.HookContext) error {
+		lookups++
+		return nil
+	})
+
+	alice := PopulateCustomer{Name: "alice"}
+	bob := PopulateCustomer{Name: "bob"}
+	testutil.AssertNoError(t, customers.Create(ctx, &alice), "Failed to create customer")
+	testutil.AssertNoError(t, customers.Create(ctx, &bob), "Failed to create customer")
+
+	for _, order := range []PopulateOrder{
+		{Number: "A-1", CustomerID: alice.ID, ContactIDs: []primitive.ObjectID{bob.ID}},
+		{Number: "A-2", CustomerID: bob.ID, ContactIDs: []primitive.ObjectID{alice.ID, bob.ID}},
+		{Number: "A-3", CustomerID: primitive.NewObjectID()},
+	} {
+		testutil.AssertNoError(t, orders.Create(ctx, &order), "Failed to create order")
+	}
+
+	results, err := orders.FindWithQuery(ctx,
+		query.New().SortBy("number", true).Populate("customerId", "contactIds"))
+	testutil.AssertNoError(t, err, "FindWithQuery failed")
+	testutil.AssertEqual(t, 1, lookups, "References to one model should be loaded with a single query")
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 orders, got %d", len(results))
+	}
+	if results[0].Customer == nil || results[0].Customer.Name != "alice" {
+		t.Errorf("expected order A-1 to be populated with alice, got %+v", results[0].Customer)
+	}
+	if len(results[1].Contacts) != 2 || results[1].Contacts[0].Name != "alice" || results[1].Contacts[1].Name != "bob" {
+		t.Errorf("expected order A-2 contacts in reference order, got %+v", results[1].Contacts)
+	}
+	if results[2].Customer != nil {
+		t.Errorf("expected missing customer to stay nil, got %+v", results[2].Customer)
+	}
+
+	// Raw documents get the reference replaced
+	var raw bson.M
+	testutil.AssertNoError(t, orders.Model.FindOne(ctx, bson.M{"number": "A-1"}, &raw), "FindOne failed")
+	testutil.AssertNoError(t, orders.Populate(ctx, raw, "customerId"), "Populate failed")
+	if customer, ok := raw["customerId"].(bson.M); !ok || customer["name"] != "alice" {
+		t.Errorf("expected customerId to be replaced with the customer, got %v", raw["customerId"])
+	}
+
+	// Only reference fields can be populated
+	_, err = orders.FindWithQuery(ctx, query.New().Populate("number"))
+	if !errors.IsValidationError(err) {
+		t.Errorf("expected validation error for a non-reference field, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SoftDeleteUser struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Username  string             `bson:"username" schema:"required"`
+	Role      string             `bson:"role"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty" schema:"softdelete"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+func setupSoftDeleteModel(t *testing.T, collName string) (*model.GenericModel[SoftDeleteUser], []SoftDeleteUser, func()) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.GenerateFromStruct(SoftDeleteUser{}, schema.WithCollection(collName))
+	m := model.NewGeneric[SoftDeleteUser]("SoftDeleteUser", s, client.Database)
+
+	users := []SoftDeleteUser{
+		{Username: "alice", Role: "admin"},
+		{Username: "bob", Role: "user"},
+		{Username: "carol", Role: "user"},
+	}
+	for i := range users {
+		if err := m.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	return m, users, func() {
+		testutil.DropCollection(t, client.Database, collName)
+		cleanup()
+	}
+}
+
+func TestSoftDelete_DeleteByIdKeepsDocument(t *testing.T) {
+	ctx := context.Background()
+	m, users, cleanup := setupSoftDeleteModel(t, "users_soft_delete_by_id")
+	defer cleanup()
+
+	if err := m.DeleteById(ctx, users[1].ID.Hex()); err != nil {
+		t.Fatalf("DeleteById failed: %v", err)
+	}
+
+	// The document is hidden from regular reads
+	if _, err := m.FindById(ctx, users[1].ID.Hex()); !errors.IsNotFound(err) {
+		t.Errorf("expected soft-deleted document to be not found, got %v", err)
+	}
+
+	count, err := m.Count(ctx, bson.M{})
+	if err != nil || count != 2 {
+		t.Errorf("expected Count to exclude soft-deleted documents, got %d (err=%v)", count, err)
+	}
+
+	// Deleting it again reports not found
+	if err := m.DeleteById(ctx, users[1].ID.Hex()); !errors.IsNotFound(err) {
+		t.Errorf("expected second delete to return not found, got %v", err)
+	}
+
+	// But it is still stored with a deletedAt timestamp
+	var raw bson.M
+	if err := m.Collection.FindOne(ctx, bson.M{"_id": users[1].ID}).Decode(&raw); err != nil {
+		t.Fatalf("expected soft-deleted document to remain in the collection: %v", err)
+	}
+	if raw["deletedAt"] == nil {
+		t.Error("expected deletedAt to be set")
+	}
+}
+
+func TestSoftDelete_QueryScopes(t *testing.T) {
+	ctx := context.Background()
+	m, _, cleanup := setupSoftDeleteModel(t, "users_soft_delete_scopes")
+	defer cleanup()
+
+	deleted, err := m.DeleteWithQuery(ctx, query.New().Where("role", "user"))
+	if err != nil {
+		t.Fatalf("DeleteWithQuery failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 soft-deleted documents, got %d", deleted)
+	}
+
+	active, err := m.FindWithQuery(ctx, query.New())
+	if err != nil || len(active) != 1 {
+		t.Errorf("expected 1 active document, got %d (err=%v)", len(active), err)
+	}
+
+	all, err := m.FindWithQuery(ctx, query.New().WithDeleted())
+	if err != nil || len(all) != 3 {
+		t.Errorf("expected 3 documents with WithDeleted, got %d (err=%v)", len(all), err)
+	}
+
+	onlyDeleted, err := m.CountWithQuery(ctx, query.New().OnlyDeleted())
+	if err != nil || onlyDeleted != 2 {
+		t.Errorf("expected 2 documents with OnlyDeleted, got %d (err=%v)", onlyDeleted, err)
+	}
+}
+
+func TestSoftDelete_RestoreAndHardDelete(t *testing.T) {
+	ctx := context.Background()
+	m, users, cleanup := setupSoftDeleteModel(t, "users_soft_delete_restore")
+	defer cleanup()
+
+	id := users[2].ID.Hex()
+	if err := m.DeleteById(ctx, id); err != nil {
+		t.Fatalf("DeleteById failed: %v", err)
+	}
+
+	if err := m.Restore(ctx, id); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	restored, err := m.FindById(ctx, id)
+	if err != nil {
+		t.Fatalf("expected restored document to be found: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Error("expected deletedAt to be cleared")
+	}
+
+	// Restoring a document that is not deleted reports not found
+	if err := m.Restore(ctx, id); !errors.IsNotFound(err) {
+		t.Errorf("expected restore of active document to return not found, got %v", err)
+	}
+
+	if err := m.HardDelete(ctx, id); err != nil {
+		t.Fatalf("HardDelete failed: %v", err)
+	}
+
+	count, err := m.CountWithQuery(ctx, query.New().WithDeleted())
+	if err != nil || count != 2 {
+		t.Errorf("expected hard-deleted document to be removed, got %d (err=%v)", count, err)
+	}
+}
@@ -0,0 +1,263 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TaggedUser struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Username  string             `bson:"username"`
+	Email     string             `bson:"email"`
+	Age       int                `bson:"age"`
+	Active    bool               `bson:"active"`
+	Role      string             `bson:"role"`
+	Tags      []string           `bson:"tags"`
+	Logins    int                `bson:"logins"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+func TestUpdateOperators_UpdateById(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_update_operators"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+	m := model.NewGeneric[TaggedUser]("TaggedUser", s, client.Database)
+
+	user := &TaggedUser{Username: "operators", Email: "operators@example.com", Age: 30, Role: "user", Tags: []string{"a"}}
+	if err := m.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	err := m.UpdateById(ctx, user.ID.Hex(), bson.M{
+		"$inc":   bson.M{"logins": 2, "age": 1},
+		"$push":  bson.M{"tags": bson.M{"$each": bson.A{"b", "c"}}},
+		"$unset": bson.M{"role": ""},
+		"$set":   bson.M{"active": true},
+	})
+	testutil.AssertNoError(t, err, "Failed to update with operators")
+
+	updated, err := m.FindById(ctx, user.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find user")
+	testutil.AssertEqual(t, 2, updated.Logins, "Logins should be incremented")
+	testutil.AssertEqual(t, 31, updated.Age, "Age should be incremented")
+	testutil.AssertEqual(t, 3, len(updated.Tags), "Tags should be pushed")
+	testutil.AssertEqual(t, "", updated.Role, "Role should be unset")
+	testutil.AssertEqual(t, true, updated.Active, "Active should be set")
+	testutil.AssertEqual(t, "operators", updated.Username, "Username should be preserved")
+	if updated.UpdatedAt.Before(user.UpdatedAt) {
+		t.Error("expected updatedAt to be refreshed")
+	}
+
+	// $addToSet and $pull keep the array consistent
+	err = m.UpdateById(ctx, user.ID.Hex(), bson.M{
+		"$addToSet": bson.M{"tags": "a"},
+		"$max":      bson.M{"age": 50},
+	})
+	testutil.AssertNoError(t, err, "Failed to update with $addToSet and $max")
+	err = m.UpdateById(ctx, user.ID.Hex(), bson.M{"$pull": bson.M{"tags": "b"}})
+	testutil.AssertNoError(t, err, "Failed to update with $pull")
+
+	updated, err = m.FindById(ctx, user.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find user")
+	testutil.AssertEqual(t, 2, len(updated.Tags), "Tags should not contain duplicates or pulled values")
+	testutil.AssertEqual(t, 50, updated.Age, "Age should be raised by $max")
+}
+
+func TestUpdateOperators_ValidationAndErrors(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_update_operators_validation"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+
+	user := &testutil.TestUser{Username: "validated", Email: "validated@example.com", Age: 20, Role: "user"}
+	if err := m.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// The operators are simulated before validation, so age 20 - 5 violates the minimum
+	err := m.UpdateById(ctx, user.ID.Hex(), bson.M{"$inc": bson.M{"age": -5}})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	_, err = m.UpdateWithQuery(ctx, query.New().Where("username", "validated"), bson.M{"$unset": bson.M{"username": ""}})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	err = m.UpdateById(ctx, user.ID.Hex(), bson.M{"$where": bson.M{"age": 1}})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	err = m.UpdateById(ctx, user.ID.Hex(), bson.M{"$set": bson.M{"age": 21}, "role": "admin"})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	// Nothing was written
+	unchanged, err := m.FindById(ctx, user.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find user")
+	testutil.AssertEqual(t, 20, unchanged.Age, "Age should not change")
+	testutil.AssertEqual(t, "validated", unchanged.Username, "Username should not change")
+}
+
+func TestUpdateOperators_UpdateBuilder(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_update_builder"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := testutil.CreateTestSchema(collName)
+	m := model.NewGeneric[TaggedUser]("TaggedUser", s, client.Database)
+
+	user := &TaggedUser{Username: "builder", Email: "builder@example.com", Age: 30, Role: "user"}
+	if err := m.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	err := m.UpdateById(ctx, user.ID.Hex(), query.Update().Inc("logins", 1).AddToSet("tags", "beta", "beta"))
+	testutil.AssertNoError(t, err, "Failed to update with builder")
+
+	modified, err := m.UpdateWithQuery(ctx, query.New().Where("username", "builder"), query.Update().Set("role", "editor"))
+	testutil.AssertNoError(t, err, "Failed to update with builder")
+	testutil.AssertEqual(t, int64(1), modified, "One document should be modified")
+
+	updated, err := m.FindById(ctx, user.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find user")
+	testutil.AssertEqual(t, 1, updated.Logins, "Logins should be incremented")
+	testutil.AssertEqual(t, 1, len(updated.Tags), "Tags should be added once")
+	testutil.AssertEqual(t, "editor", updated.Role, "Role should be set")
+
+	// Builder errors are returned before anything is written
+	err = m.UpdateById(ctx, user.ID.Hex(), query.Update().Inc("logins", "many"))
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+}
+
+func TestUpdateById_RejectsMismatchedTypes(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "users_update_types"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	// A model without a model type has no struct to decode updates into
+	s := schema.New(map[string]schema.Field{
+		"username": {Type: ""},
+		"age":      {Type: 0},
+	}, schema.WithCollection(collName))
+	m := model.New("UntypedUser", s, client.Database)
+
+	user := &TaggedUser{Username: "typed", Email: "typed@example.com", Age: 30}
+	if err := m.Create(ctx, user); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	err := m.UpdateById(ctx, user.ID.Hex(), bson.M{"$set": bson.M{"age": "thirty"}})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if len(validationErr.Errors) != 1 || validationErr.Errors[0].Path != "age" || validationErr.Errors[0].Rule != "type" {
+		t.Errorf("Expected a type error for age, got %+v", validationErr.Errors)
+	}
+
+	// The same check applies to updates of several documents
+	_, err = m.UpdateWithQuery(ctx, query.New().Where("username", "typed"), bson.M{"$set": bson.M{"username": 42}})
+	if !errors.IsValidationError(err) {
+		t.Errorf("Expected a validation error from UpdateWithQuery, got %v", err)
+	}
+
+	var stored TaggedUser
+	testutil.AssertNoError(t, m.FindById(ctx, user.ID.Hex(), &stored), "Failed to find user")
+	testutil.AssertEqual(t, 30, stored.Age, "Age should not be updated")
+	testutil.AssertEqual(t, "typed", stored.Username, "Username should not be updated")
+}
+
+type OrderLine struct {
+	SKU      string `bson:"sku"`
+	Quantity int    `bson:"quantity"`
+}
+
+type Order struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	Lines  []OrderLine        `bson:"lines"`
+	Scores []int              `bson:"scores"`
+}
+
+func TestUpdateOperators_PositionalPaths(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "orders_positional"
+	testutil.DropCollection(t, client.Database, collName)
+	defer testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{
+		"lines": {Schema: schema.New(map[string]schema.Field{
+			"sku":      {Type: "", Required: true},
+			"quantity": {Type: 0, Min: 1, HasMin: true, Max: 10},
+		})},
+		"scores": {MaxItems: 3},
+	}, schema.WithCollection(collName), schema.WithModelType(Order{}))
+	m := model.NewGeneric[Order]("Order", s, client.Database)
+
+	order := &Order{Lines: []OrderLine{{SKU: "A", Quantity: 1}, {SKU: "B", Quantity: 2}}, Scores: []int{5, 1}}
+	if err := m.Create(ctx, order); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// $ updates the element matched by the query
+	_, err := m.UpdateWithQuery(ctx, query.New().Where("_id", order.ID).Where("lines.sku", "B"),
+		bson.M{"$set": bson.M{"lines.$.quantity": 5}})
+	testutil.AssertNoError(t, err, "Failed to update with $")
+
+	// $[] updates every element
+	err = m.UpdateById(ctx, order.ID.Hex(), bson.M{"$inc": bson.M{"lines.$[].quantity": 1}})
+	testutil.AssertNoError(t, err, "Failed to update with $[]")
+
+	updated, err := m.FindById(ctx, order.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find order")
+	testutil.AssertEqual(t, 2, updated.Lines[0].Quantity, "First line should be incremented")
+	testutil.AssertEqual(t, 6, updated.Lines[1].Quantity, "Second line should be set and incremented")
+
+	// The rules of the element schema apply to positional values
+	err = m.UpdateById(ctx, order.ID.Hex(), bson.M{"$set": bson.M{"lines.$[].quantity": 0}})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+	_, err = m.UpdateWithQuery(ctx, query.New().Where("_id", order.ID).Where("lines.sku", "A"),
+		bson.M{"$set": bson.M{"lines.$.quantity": 11}})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	// $push modifiers are simulated like the server applies them: $slice keeps the
+	// array within maxItems
+	err = m.UpdateById(ctx, order.ID.Hex(), bson.M{"$push": bson.M{"scores": bson.M{
+		"$each": bson.A{9, 3}, "$position": 0, "$sort": -1, "$slice": 3,
+	}}})
+	testutil.AssertNoError(t, err, "Failed to push with modifiers")
+
+	updated, err = m.FindById(ctx, order.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to find order")
+	if len(updated.Scores) != 3 || updated.Scores[0] != 9 || updated.Scores[1] != 5 || updated.Scores[2] != 3 {
+		t.Errorf("Expected scores to be sorted and sliced to [9 5 3], got %v", updated.Scores)
+	}
+}
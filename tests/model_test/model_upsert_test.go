@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/query"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func setupUpsertModel(t *testing.T, collName string) (*model.GenericModel[testutil.TestUser], func()) {
+	client, cleanup := testutil.CreateTestClient(t)
+	testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{
+		"username": {Required: true},
+		"email":    {Required: true},
+		"age":      {Min: 18},
+		"role":     {Default: "member"},
+	}, schema.WithCollection(collName))
+
+	m := model.NewGeneric[testutil.TestUser]("TestUser", s, client.Database)
+	return m, func() {
+		testutil.DropCollection(t, client.Database, collName)
+		cleanup()
+	}
+}
+
+func TestUpsert_InsertsThenUpdates(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupUpsertModel(t, "users_upsert")
+	defer cleanup()
+
+	filter := bson.M{"username": "synced"}
+
+	user, inserted, err := m.Upsert(ctx, filter, bson.M{"email": "synced@example.com", "age": 30})
+	testutil.AssertNoError(t, err, "Failed to upsert new document")
+	testutil.AssertEqual(t, true, inserted, "Document should be inserted")
+	testutil.AssertEqual(t, "synced", user.Username, "Username should come from the filter")
+	testutil.AssertEqual(t, "member", user.Role, "Default should be applied on insert")
+	if user.ID.IsZero() || user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Errorf("expected ID and timestamps to be set, got %+v", user)
+	}
+
+	updated, inserted, err := m.Upsert(ctx, filter, query.Update().Set("role", "admin").Inc("age", 1))
+	testutil.AssertNoError(t, err, "Failed to upsert existing document")
+	testutil.AssertEqual(t, false, inserted, "Document should be updated")
+	testutil.AssertEqual(t, user.ID, updated.ID, "The same document should be updated")
+	testutil.AssertEqual(t, 31, updated.Age, "Age should be incremented")
+	testutil.AssertEqual(t, "admin", updated.Role, "Role should be updated")
+	if !updated.CreatedAt.Equal(user.CreatedAt) {
+		t.Errorf("expected createdAt to be kept, got %v and %v", user.CreatedAt, updated.CreatedAt)
+	}
+
+	count, err := m.Count(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count documents")
+	testutil.AssertEqual(t, int64(1), count, "Only one document should exist")
+}
+
+func TestUpsert_ValidatesResultingDocument(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupUpsertModel(t, "users_upsert_validation")
+	defer cleanup()
+
+	// The inserted document would lack the required email
+	_, _, err := m.Upsert(ctx, bson.M{"username": "incomplete"}, bson.M{"age": 30})
+	testutil.AssertErrorType(t, err, errors.IsValidationError, "validation")
+
+	count, err := m.Count(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count documents")
+	testutil.AssertEqual(t, int64(0), count, "Nothing should be written")
+}
@@ -417,3 +417,160 @@ func TestCustomValidatorBehavior(t *testing.T) {
 		t.Errorf("Expected multiple validation error messages, got: %v", err)
 	}
 }
+
+func TestCreateAppliesDefaults(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "create_defaults_test"
+	testutil.DropCollection(t, client.Database, collName) // Clean up
+
+	type Member struct {
+		ID       primitive.ObjectID `bson:"_id,omitempty"`
+		Name     string             `bson:"name" schema:"required"`
+		Role     string             `bson:"role" schema:"required,default=member"`
+		JoinedAt time.Time          `bson:"joinedAt"`
+	}
+
+	s := schema.GenerateFromStruct(Member{}, schema.WithCollection(collName))
+	joinedAt := s.Fields["joinedAt"]
+	joinedAt.Default = func() interface{} { return time.Now() }
+	s.Fields["joinedAt"] = joinedAt
+
+	memberModel := model.NewGeneric[Member]("Member", s, client.Database)
+
+	// Defaults are applied before validation, so the required role passes
+	member := &Member{Name: "alice"}
+	if err := memberModel.Create(ctx, member); err != nil {
+		t.Fatalf("Failed to create member: %v", err)
+	}
+	if member.Role != "member" || member.JoinedAt.IsZero() {
+		t.Errorf("Expected defaults to be applied, got %+v", member)
+	}
+
+	found, err := memberModel.FindById(ctx, member.ID.Hex())
+	if err != nil {
+		t.Fatalf("Failed to find member: %v", err)
+	}
+	if found.Role != "member" {
+		t.Errorf("Expected default to be stored, got %q", found.Role)
+	}
+
+	// Values that are set are kept
+	admin := &Member{Name: "bob", Role: "admin"}
+	if err := memberModel.Create(ctx, admin); err != nil {
+		t.Fatalf("Failed to create admin: %v", err)
+	}
+	if admin.Role != "admin" {
+		t.Errorf("Expected role to be kept, got %q", admin.Role)
+	}
+}
+
+func TestMapDocumentValidation(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "map_validation_test"
+	testutil.DropCollection(t, client.Database, collName) // Clean up
+
+	s := schema.New(
+		map[string]schema.Field{
+			"title":  {Required: true, Type: ""},
+			"rating": {Type: 0, Min: 1, Max: 5},
+			"author": {Schema: schema.New(map[string]schema.Field{
+				"name": {Required: true},
+			})},
+		},
+		schema.WithCollection(collName),
+		schema.WithTimestamps(true),
+	)
+	m := model.New("Review", s, client.Database)
+
+	// Valid map documents are created with their ID and timestamps
+	review := bson.M{"title": "Great", "rating": 5, "author": bson.M{"name": "Alice"}}
+	if err := m.Create(ctx, review); err != nil {
+		t.Fatalf("Failed to create map document: %v", err)
+	}
+	id, ok := review["_id"].(primitive.ObjectID)
+	if !ok {
+		t.Fatalf("Expected the inserted ID to be set on the map, got %v", review["_id"])
+	}
+	if _, ok := review["createdAt"].(time.Time); !ok {
+		t.Error("Expected createdAt to be set on the map")
+	}
+
+	// Invalid map documents report every failing field
+	err := m.Create(ctx, map[string]interface{}{"rating": 9, "author": map[string]interface{}{}})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if paths := validationErr.Paths(); len(paths) != 3 || paths[0] != "author.name" || paths[1] != "rating" || paths[2] != "title" {
+		t.Errorf("Unexpected failed paths %v", paths)
+	}
+
+	// Updates of models without a model type are validated as maps
+	err = m.UpdateById(ctx, id.Hex(), bson.M{"$set": bson.M{"rating": 0}})
+	if !errors.IsValidationError(err) || !strings.Contains(err.Error(), "less than minimum") {
+		t.Errorf("Expected the update to fail validation, got %v", err)
+	}
+
+	var stored bson.M
+	if err := m.FindById(ctx, id.Hex(), &stored); err != nil {
+		t.Fatalf("Failed to find document: %v", err)
+	}
+	if fmt.Sprint(stored["rating"]) != "5" {
+		t.Errorf("Expected rating to be unchanged, got %v", stored["rating"])
+	}
+}
+
+func TestStringRulesAndTransforms(t *testing.T) {
+	ctx := context.Background()
+	client, cleanup := testutil.CreateTestClient(t)
+	defer cleanup()
+
+	collName := "string_rules_test"
+	testutil.DropCollection(t, client.Database, collName) // Clean up
+
+	type Subscriber struct {
+		ID    primitive.ObjectID `bson:"_id,omitempty"`
+		Email string             `bson:"email" schema:"required,trim,lowercase,format=email"`
+		Code  string             `bson:"code" schema:"uppercase,minlen=3,maxlen=6"`
+	}
+
+	s := schema.GenerateFromStruct(Subscriber{}, schema.WithCollection(collName))
+	m := model.NewGeneric[Subscriber]("Subscriber", s, client.Database)
+
+	// Strings are transformed before they are validated and stored
+	sub := &Subscriber{Email: "  Alice@Example.COM ", Code: "ab12"}
+	if err := m.Create(ctx, sub); err != nil {
+		t.Fatalf("Failed to create subscriber: %v", err)
+	}
+	if sub.Email != "alice@example.com" || sub.Code != "AB12" {
+		t.Errorf("Expected transformed strings, got %+v", sub)
+	}
+
+	// Updates are transformed too
+	if err := m.UpdateById(ctx, sub.ID.Hex(), bson.M{"$set": bson.M{"email": " BOB@EXAMPLE.COM"}}); err != nil {
+		t.Fatalf("Failed to update email: %v", err)
+	}
+	found, err := m.FindById(ctx, sub.ID.Hex())
+	if err != nil {
+		t.Fatalf("Failed to find subscriber: %v", err)
+	}
+	if found.Email != "bob@example.com" {
+		t.Errorf("Expected transformed email to be stored, got %q", found.Email)
+	}
+
+	// Invalid formats and lengths are reported together
+	err = m.Create(ctx, &Subscriber{Email: "not-an-email", Code: "ab"})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok || len(validationErr.Errors) != 2 {
+		t.Fatalf("Expected format and length errors, got %v", err)
+	}
+	if validationErr.Errors[0].Rule != "minLength" || validationErr.Errors[1].Rule != "format" {
+		t.Errorf("Unexpected failures %+v", validationErr.Errors)
+	}
+}
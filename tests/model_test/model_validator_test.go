@@ -0,0 +1,85 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestApplyCollectionValidator(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := prepareCleanDatabase(t, "validated_products")
+	defer cleanup()
+	defer testutil.DropCollection(t, db, "validated_products")
+
+	s := schema.New(map[string]schema.Field{
+		"name":  {Type: "", Required: true},
+		"price": {Type: 0.0, Min: 0, HasMin: true},
+	}, schema.WithCollection("validated_products"))
+	m := model.New("ValidatedProduct", s, db, model.Options{SkipIndexes: true})
+
+	// The collection doesn't exist yet, so it is created with the validator
+	err := m.ApplyCollectionValidator(ctx, model.ValidationLevelStrict, model.ValidationActionError)
+	testutil.AssertNoError(t, err, "Failed to apply the validator to a new collection")
+
+	// Writes bypassing the model are validated by the server
+	_, err = m.Collection.InsertOne(ctx, bson.M{"name": "Pen", "price": -1.0})
+	if err == nil {
+		t.Error("Expected the server to reject a negative price")
+	}
+	_, err = m.Collection.InsertOne(ctx, bson.M{"price": 1.0})
+	if err == nil {
+		t.Error("Expected the server to reject a product without a name")
+	}
+	_, err = m.Collection.InsertOne(ctx, bson.M{"name": "Pen", "price": 1.5})
+	testutil.AssertNoError(t, err, "A valid product should be accepted")
+
+	// The validator of an existing collection is replaced with collMod
+	err = m.ApplyCollectionValidator(ctx, model.ValidationLevelModerate, model.ValidationActionWarn)
+	testutil.AssertNoError(t, err, "Failed to modify the validator of an existing collection")
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": "validated_products"})
+	testutil.AssertNoError(t, err, "Failed to list collections")
+	if len(specs) != 1 {
+		t.Fatalf("Expected the collection to exist, got %v", specs)
+	}
+	var opts bson.M
+	testutil.AssertNoError(t, bson.Unmarshal(specs[0].Options, &opts), "Failed to decode collection options")
+	if opts["validationAction"] != model.ValidationActionWarn || opts["validator"] == nil {
+		t.Errorf("Expected the validator to warn, got %v", opts)
+	}
+}
+
+func TestApplyCollectionValidator_MapDocuments(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := prepareCleanDatabase(t, "validated_stock")
+	defer cleanup()
+	defer testutil.DropCollection(t, db, "validated_stock")
+
+	s := schema.New(map[string]schema.Field{
+		"name":  {Type: "", Required: true},
+		"stock": {Type: 0, Required: true},
+	}, schema.WithCollection("validated_stock"), schema.WithTimestamps(false))
+	m := model.New("ValidatedStock", s, db, model.Options{SkipIndexes: true})
+
+	err := m.ApplyCollectionValidator(ctx, model.ValidationLevelStrict, model.ValidationActionError)
+	testutil.AssertNoError(t, err, "Failed to apply the validator")
+
+	// Decoded JSON numbers are whole doubles, accepted by the model and the server alike
+	err = m.Create(ctx, bson.M{"name": "Pen", "stock": 3.0})
+	testutil.AssertNoError(t, err, "A whole double should be accepted")
+
+	// Fractional doubles are rejected by both
+	err = m.Create(ctx, bson.M{"name": "Pen", "stock": 2.5})
+	if err == nil {
+		t.Error("Expected the model to reject a fractional stock")
+	}
+	_, err = m.Collection.InsertOne(ctx, bson.M{"name": "Pen", "stock": 2.5})
+	if err == nil {
+		t.Error("Expected the server to reject a fractional stock")
+	}
+}
@@ -0,0 +1,235 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/model"
+	"github.com/isimtekin/merhongo/schema"
+	"github.com/isimtekin/merhongo/tests/testutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VersionedAccount struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Owner     string             `bson:"owner"`
+	Balance   int                `bson:"balance"`
+	Version   int                `bson:"__v"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+func setupVersionedModel(t *testing.T, collName string) (*model.GenericModel[VersionedAccount], func()) {
+	client, cleanup := testutil.CreateTestClient(t)
+	testutil.DropCollection(t, client.Database, collName)
+
+	s := schema.New(map[string]schema.Field{
+		"owner": {Required: true},
+	}, schema.WithCollection(collName), schema.WithVersionKey("__v"))
+
+	m := model.NewGeneric[VersionedAccount]("VersionedAccount", s, client.Database)
+	return m, func() {
+		testutil.DropCollection(t, client.Database, collName)
+		cleanup()
+	}
+}
+
+func TestVersionKey_SaveDetectsConflicts(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_save")
+	defer cleanup()
+
+	account := &VersionedAccount{Owner: "alice", Balance: 100}
+	testutil.AssertNoError(t, m.Save(ctx, account), "Failed to create account with Save")
+	testutil.AssertEqual(t, 0, account.Version, "New documents should start at version 0")
+
+	// Two writers load the same version
+	first, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	second, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+
+	first.Balance += 50
+	testutil.AssertNoError(t, m.Save(ctx, first), "First save should succeed")
+	testutil.AssertEqual(t, 1, first.Version, "Save should increment the version")
+
+	second.Balance -= 30
+	err = m.Save(ctx, second)
+	testutil.AssertErrorType(t, err, errors.IsVersionConflict, "version conflict")
+
+	stored, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	testutil.AssertEqual(t, 150, stored.Balance, "The stale write should not be applied")
+	testutil.AssertEqual(t, 1, stored.Version, "The version should match the successful write")
+}
+
+func TestVersionKey_UpdateByIdIncrementsVersion(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_update")
+	defer cleanup()
+
+	account := &VersionedAccount{Owner: "bob", Balance: 10}
+	testutil.AssertNoError(t, m.Create(ctx, account), "Failed to create account")
+
+	err := m.UpdateById(ctx, account.ID.Hex(), bson.M{"$inc": bson.M{"balance": 5}, "$set": bson.M{"__v": 42}})
+	testutil.AssertNoError(t, err, "Failed to update account")
+
+	stored, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	testutil.AssertEqual(t, 15, stored.Balance, "Balance should be incremented")
+	testutil.AssertEqual(t, 1, stored.Version, "Version should be incremented, not set by the caller")
+}
+
+func TestVersionKey_FindOneAndUpdateIncrementsVersion(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_findoneandupdate")
+	defer cleanup()
+
+	account := &VersionedAccount{Owner: "carol", Balance: 10}
+	testutil.AssertNoError(t, m.Create(ctx, account), "Failed to create account")
+	stale, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+
+	updated, err := m.FindOneAndUpdate(ctx, bson.M{"owner": "carol"}, bson.M{"$inc": bson.M{"balance": 5}})
+	testutil.AssertNoError(t, err, "Failed to update account")
+	testutil.AssertEqual(t, 1, updated.Version, "FindOneAndUpdate should increment the version")
+
+	// A copy loaded before the update can't overwrite it
+	stale.Balance = 0
+	testutil.AssertErrorType(t, m.Save(ctx, stale), errors.IsVersionConflict, "version conflict")
+}
+
+func TestVersionKey_ReplaceIncrementsVersion(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_replace")
+	defer cleanup()
+
+	account := &VersionedAccount{Owner: "dave", Balance: 10}
+	testutil.AssertNoError(t, m.Create(ctx, account), "Failed to create account")
+	stale, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+
+	replaced, err := m.FindOneAndReplace(ctx, bson.M{"_id": account.ID},
+		&VersionedAccount{Owner: "dave", Balance: 20, Version: 0})
+	testutil.AssertNoError(t, err, "Failed to replace account")
+	testutil.AssertEqual(t, 1, replaced.Version, "FindOneAndReplace should increment the version")
+
+	// Neither a stale Save nor a stale replacement overwrites the replace
+	stale.Balance = 0
+	testutil.AssertErrorType(t, m.Save(ctx, stale), errors.IsVersionConflict, "version conflict")
+	_, err = m.FindOneAndReplace(ctx, bson.M{"_id": account.ID},
+		&VersionedAccount{Owner: "dave", Balance: 0, Version: 0})
+	testutil.AssertErrorType(t, err, errors.IsVersionConflict, "version conflict")
+
+	// Bulk replaces continue the version too
+	_, err = m.BulkWrite(ctx, []model.BulkOperation{model.ReplaceOneOperation{
+		Filter:      bson.M{"_id": account.ID},
+		Replacement: &VersionedAccount{Owner: "dave", Balance: 30, Version: 1},
+	}})
+	testutil.AssertNoError(t, err, "Failed to bulk replace account")
+
+	stored, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	testutil.AssertEqual(t, 30, stored.Balance, "The bulk replace should be applied")
+	testutil.AssertEqual(t, 2, stored.Version, "The bulk replace should increment the version")
+}
+
+func TestVersionKey_SavePreHooksChangeUpdate(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_save_hooks")
+	defer cleanup()
+
+	// Pre-update hooks receive the update Save builds and may change it
+	m.Schema.PreHook("update", func(hc *schema.HookContext) error {
+		if hc.Operation != "Save" {
+			return nil
+		}
+		set, ok := hc.Update["$set"].(bson.M)
+		if !ok {
+			t.Fatalf("Expected the update to be built before the hooks, got %v", hc.Update)
+		}
+		set["owner"] = "audited"
+		return nil
+	})
+
+	account := &VersionedAccount{Owner: "erin", Balance: 10}
+	testutil.AssertNoError(t, m.Create(ctx, account), "Failed to create account")
+
+	account.Balance = 15
+	testutil.AssertNoError(t, m.Save(ctx, account), "Failed to save account")
+
+	stored, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	testutil.AssertEqual(t, 15, stored.Balance, "The document should be saved")
+	testutil.AssertEqual(t, "audited", stored.Owner, "The hook's change should be saved")
+	testutil.AssertEqual(t, 1, stored.Version, "Save should increment the version")
+}
+
+func TestVersionKey_Upsert(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_upsert")
+	defer cleanup()
+
+	// Upserted documents start at the version Create gives them
+	inserted, wasInserted, err := m.Upsert(ctx, bson.M{"owner": "frank"}, bson.M{"$inc": bson.M{"balance": 5}})
+	testutil.AssertNoError(t, err, "Failed to upsert new account")
+	testutil.AssertEqual(t, true, wasInserted, "Account should be inserted")
+	testutil.AssertEqual(t, 0, inserted.Version, "Upserted documents should start at version 0")
+
+	updated, wasInserted, err := m.Upsert(ctx, bson.M{"owner": "frank"}, bson.M{"$inc": bson.M{"balance": 5}})
+	testutil.AssertNoError(t, err, "Failed to upsert existing account")
+	testutil.AssertEqual(t, false, wasInserted, "Account should be updated")
+	testutil.AssertEqual(t, 10, updated.Balance, "Balance should be incremented")
+	testutil.AssertEqual(t, 1, updated.Version, "Upsert should increment the version")
+
+	// Another writer changes the account after it was validated
+	m.Schema.PreHook("validate", func(hc *schema.HookContext) error {
+		if hc.Operation != "Upsert" {
+			return nil
+		}
+		_, err := m.Collection.UpdateOne(hc.Ctx, bson.M{"_id": updated.ID},
+			bson.M{"$set": bson.M{"balance": 100}, "$inc": bson.M{"__v": 1}})
+		return err
+	})
+
+	_, _, err = m.Upsert(ctx, bson.M{"owner": "frank"}, bson.M{"$inc": bson.M{"balance": 5}})
+	testutil.AssertErrorType(t, err, errors.IsVersionConflict, "version conflict")
+
+	stored, err := m.FindById(ctx, updated.ID.Hex())
+	testutil.AssertNoError(t, err, "Failed to load account")
+	testutil.AssertEqual(t, 100, stored.Balance, "The stale upsert should not be applied")
+
+	count, err := m.Count(ctx, bson.M{})
+	testutil.AssertNoError(t, err, "Failed to count accounts")
+	testutil.AssertEqual(t, int64(1), count, "The stale upsert should not insert a document")
+}
+
+func TestVersionKey_FindOneAndDeletePinsCheckedDocument(t *testing.T) {
+	ctx := context.Background()
+	m, cleanup := setupVersionedModel(t, "accounts_version_findoneanddelete")
+	defer cleanup()
+
+	account := &VersionedAccount{Owner: "gina", Balance: 0}
+	testutil.AssertNoError(t, m.Create(ctx, account), "Failed to create account")
+
+	// The middleware only allows deleting empty accounts, and another writer funds the
+	// account right after it was checked
+	m.Schema.Pre("delete", func(doc interface{}) error {
+		if doc.(*VersionedAccount).Balance != 0 {
+			return errors.WithDetails(errors.ErrValidation, "account is not empty")
+		}
+		_, err := m.Collection.UpdateOne(ctx, bson.M{"_id": account.ID},
+			bson.M{"$set": bson.M{"balance": 50}, "$inc": bson.M{"__v": 1}})
+		return err
+	})
+
+	_, err := m.FindOneAndDelete(ctx, bson.M{"owner": "gina"})
+	testutil.AssertErrorType(t, err, errors.IsVersionConflict, "version conflict")
+
+	stored, err := m.FindById(ctx, account.ID.Hex())
+	testutil.AssertNoError(t, err, "The funded account should not be deleted")
+	testutil.AssertEqual(t, 50, stored.Balance, "The concurrent change should be kept")
+}
@@ -0,0 +1,117 @@
+package query_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPipeline_Build(t *testing.T) {
+	stages, err := query.NewPipeline().
+		Match(query.New().Where("active", true)).
+		Lookup("orders", "_id", "userId", "orders").
+		Unwind("orders").
+		Group("$role", bson.M{"total": bson.M{"$sum": "$orders.amount"}}).
+		Sort("total", false).
+		Sort("_id", true).
+		Skip(5).
+		Limit(10).
+		Project(bson.M{"total": 1}).
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"active": true}}},
+		{{Key: "$lookup", Value: bson.M{"from": "orders", "localField": "_id", "foreignField": "userId", "as": "orders"}}},
+		{{Key: "$unwind", Value: "$orders"}},
+		{{Key: "$group", Value: bson.M{"_id": "$role", "total": bson.M{"$sum": "$orders.amount"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$skip", Value: int64(5)}},
+		{{Key: "$limit", Value: int64(10)}},
+		{{Key: "$project", Value: bson.M{"total": 1}}},
+	}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Errorf("expected %v, got %v", expected, stages)
+	}
+}
+
+func TestPipeline_Stages(t *testing.T) {
+	stages, err := query.NewPipeline().
+		Unwind("$tags", true).
+		AddFields(bson.M{"year": bson.M{"$year": "$createdAt"}}).
+		ReplaceRoot("profile").
+		Facet(map[string]*query.Pipeline{
+			"count": query.NewPipeline().Count("total"),
+		}).
+		Stage("$sample", bson.M{"size": 3}).
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := mongo.Pipeline{
+		{{Key: "$unwind", Value: bson.M{"path": "$tags", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$addFields", Value: bson.M{"year": bson.M{"$year": "$createdAt"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$profile"}}},
+		{{Key: "$facet", Value: bson.M{"count": mongo.Pipeline{{{Key: "$count", Value: "total"}}}}}},
+		{{Key: "$sample", Value: bson.M{"size": 3}}},
+	}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Errorf("expected %v, got %v", expected, stages)
+	}
+}
+
+func TestPipeline_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pipeline *query.Pipeline
+	}{
+		{"nil match", query.NewPipeline().Match(nil)},
+		{"match error", query.NewPipeline().Match(query.New().Where("", 1))},
+		{"group _id field", query.NewPipeline().Group("$role", bson.M{"_id": 1})},
+		{"empty projection", query.NewPipeline().Project(bson.M{})},
+		{"empty sort key", query.NewPipeline().Sort("", true)},
+		{"zero limit", query.NewPipeline().Limit(0)},
+		{"negative skip", query.NewPipeline().Skip(-1)},
+		{"empty unwind", query.NewPipeline().Unwind("$")},
+		{"incomplete lookup", query.NewPipeline().Lookup("orders", "_id", "", "orders")},
+		{"empty add fields", query.NewPipeline().AddFields(nil)},
+		{"empty facets", query.NewPipeline().Facet(nil)},
+		{"facet error", query.NewPipeline().Facet(map[string]*query.Pipeline{"n": query.NewPipeline().Limit(-1)})},
+		{"dotted count", query.NewPipeline().Count("a.b")},
+		{"empty root", query.NewPipeline().ReplaceRoot("")},
+		{"stage without operator", query.NewPipeline().Stage("sample", bson.M{"size": 3})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.pipeline.Build(); !errors.IsValidationError(err) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+
+	// The first error is kept and later stages are ignored
+	pipeline := query.NewPipeline().Limit(0).Skip(-1)
+	if pipeline.Error() == nil || pipeline.Error().Error() != "validation failed: limit must be positive" {
+		t.Errorf("expected the limit error, got %v", pipeline.Error())
+	}
+}
+
+func TestPipeline_DeletedScope(t *testing.T) {
+	if scope := query.NewPipeline().Match(query.New()).DeletedScope(); scope != query.ScopeExcludeDeleted {
+		t.Errorf("expected deleted documents to be excluded by default, got %v", scope)
+	}
+	if scope := query.NewPipeline().Match(query.New().OnlyDeleted()).DeletedScope(); scope != query.ScopeOnlyDeleted {
+		t.Errorf("expected the query's scope to apply, got %v", scope)
+	}
+	if scope := query.NewPipeline().WithDeleted().DeletedScope(); scope != query.ScopeWithDeleted {
+		t.Errorf("expected deleted documents to be included, got %v", scope)
+	}
+}
@@ -429,3 +429,58 @@ func TestQueryBuilder_NewFeatures(t *testing.T) {
 		t.Errorf("expected filter[status] to have $ne key")
 	}
 }
+
+func TestQueryBuilder_DeletedScope(t *testing.T) {
+	builder := query.New().Where("name", "john")
+	if builder.DeletedScope() != query.ScopeExcludeDeleted {
+		t.Errorf("expected default scope to exclude deleted documents, got %v", builder.DeletedScope())
+	}
+
+	builder = query.New().WithDeleted()
+	if builder.DeletedScope() != query.ScopeWithDeleted {
+		t.Errorf("expected WithDeleted scope, got %v", builder.DeletedScope())
+	}
+
+	builder = query.New().WithDeleted().OnlyDeleted()
+	if builder.DeletedScope() != query.ScopeOnlyDeleted {
+		t.Errorf("expected OnlyDeleted scope, got %v", builder.DeletedScope())
+	}
+
+	// The switches do not add anything to the filter themselves
+	filter, err := builder.GetFilter()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(filter) != 0 {
+		t.Errorf("expected empty filter, got %v", filter)
+	}
+
+	// Builders with an error keep their scope untouched
+	builder = query.WithError(errors.ErrValidation).OnlyDeleted()
+	if builder.DeletedScope() != query.ScopeExcludeDeleted {
+		t.Errorf("expected scope to be unchanged on errored builder, got %v", builder.DeletedScope())
+	}
+}
+
+func TestQueryBuilder_Populate(t *testing.T) {
+	builder := query.New().Where("status", "open").Populate("customerId", "itemIds").Populate("ownerId")
+
+	paths := builder.PopulatePaths()
+	if len(paths) != 3 || paths[0] != "customerId" || paths[1] != "itemIds" || paths[2] != "ownerId" {
+		t.Errorf("expected populate paths in call order, got %v", paths)
+	}
+
+	// Populating does not change the filter
+	filter, err := builder.GetFilter()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(filter) != 1 {
+		t.Errorf("expected only the status condition, got %v", filter)
+	}
+
+	builder = query.New().Populate("")
+	if !errors.IsValidationError(builder.Error()) {
+		t.Errorf("expected validation error for empty populate path, got %v", builder.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestUpdateBuilder_Build(t *testing.T) {
+	update, err := query.Update().
+		Set("name", "john").
+		Unset("resetToken").
+		Inc("logins", 1).
+		Push("tags", "a", "b").
+		Push("tags", "c").
+		AddToSet("roles", "editor").
+		Pull("flags", "legacy").
+		Min("lowScore", 10).
+		Max("highScore", 90).
+		CurrentDate("lastLogin").
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"$set":         "name",
+		"$unset":       "resetToken",
+		"$inc":         "logins",
+		"$push":        "tags",
+		"$addToSet":    "roles",
+		"$pull":        "flags",
+		"$min":         "lowScore",
+		"$max":         "highScore",
+		"$currentDate": "lastLogin",
+	}
+	for operator, field := range expected {
+		fields, ok := update[operator].(bson.M)
+		if !ok {
+			t.Errorf("expected %s operator in update, got %v", operator, update)
+			continue
+		}
+		if _, exists := fields[field]; !exists {
+			t.Errorf("expected %s to target %s, got %v", operator, field, fields)
+		}
+	}
+
+	// Repeated pushes to the same field accumulate
+	tags := update["$push"].(bson.M)["tags"].(bson.M)["$each"].(bson.A)
+	if len(tags) != 3 {
+		t.Errorf("expected 3 pushed tags, got %v", tags)
+	}
+}
+
+func TestUpdateBuilder_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *query.UpdateBuilder
+	}{
+		{"empty update", query.Update()},
+		{"empty key", query.Update().Set("", 1)},
+		{"non-numeric increment", query.Update().Inc("age", "one")},
+		{"push without values", query.Update().Push("tags")},
+		{"conflicting operators", query.Update().Set("age", 30).Inc("age", 1)},
+		{"initial error", query.UpdateWithError(errors.ErrValidation).Set("age", 30)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.builder.Build(); !errors.IsValidationError(err) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+
+	// The first error is kept
+	builder := query.Update().Set("", 1).Inc("age", "one")
+	if builder.Error() == nil || builder.Error().Error() != "validation failed: key cannot be empty" {
+		t.Errorf("expected first error to be kept, got %v", builder.Error())
+	}
+}
+
+func TestUpdateBuilder_Merge(t *testing.T) {
+	audit := query.Update().CurrentDate("reviewedAt")
+	update, err := query.Update().Set("status", "approved").Merge(audit).Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(update) != 2 {
+		t.Errorf("expected merged update to have 2 operators, got %v", update)
+	}
+
+	_, err = query.Update().Set("status", "approved").Merge(query.Update().Unset("status")).Build()
+	if !errors.IsValidationError(err) {
+		t.Errorf("expected conflicting merge to fail, got %v", err)
+	}
+}
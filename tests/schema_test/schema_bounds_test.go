@@ -0,0 +1,89 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNumericBounds(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"price":      {Type: 0.0, Min: 0, HasMin: true},
+		"percentage": {Type: 0.0, Min: 0, Max: 0.5, HasMin: true, HasMax: true},
+		"offset":     {Type: 0, Min: -10, Max: -1},
+		"stock":      {Type: uint(0), Min: 1, Max: 100},
+		"balance":    {Type: 0},
+	})
+
+	valid := bson.M{"price": 0.0, "percentage": 0.5, "offset": int32(-10), "stock": uint32(100), "balance": -1000}
+	if err := s.ValidateDocument(valid); err != nil {
+		t.Fatalf("Expected values on their bounds to be valid, got %v", err)
+	}
+
+	err := s.ValidateDocument(bson.M{"price": -0.5, "percentage": 0.51, "offset": 0, "stock": uint64(0)})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+
+	expected := []struct{ path, rule, message string }{
+		{"offset", "max", "field 'offset' value 0 is greater than maximum -1"},
+		{"percentage", "max", "field 'percentage' value 0.51 is greater than maximum 0.5"},
+		{"price", "min", "field 'price' value -0.5 is less than minimum 0"},
+		{"stock", "min", "field 'stock' value 0 is less than minimum 1"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d failures, got %v", len(expected), validationErr.Errors)
+	}
+	for i, e := range expected {
+		fieldErr := validationErr.Errors[i]
+		if fieldErr.Path != e.path || fieldErr.Rule != e.rule || fieldErr.Message != e.message {
+			t.Errorf("Failure %d: expected %s %q on %s, got %+v", i, e.rule, e.message, e.path, fieldErr)
+		}
+	}
+
+	if min, ok := s.Fields["price"].MinBound(); !ok || min != 0 {
+		t.Errorf("Expected price to have a zero minimum, got %v, %v", min, ok)
+	}
+	if _, ok := s.Fields["balance"].MinBound(); ok {
+		t.Error("Expected balance to have no minimum")
+	}
+}
+
+func TestArrayLengthBounds(t *testing.T) {
+	type Post struct {
+		Tags    []string `bson:"tags"`
+		Authors []string `bson:"authors"`
+	}
+
+	s := schema.New(map[string]schema.Field{
+		"tags":    {Type: []string{}, MinItems: 1, MaxItems: 3},
+		"authors": {Type: []string{}, MaxItems: 2},
+	})
+
+	if err := s.ValidateDocument(&Post{Tags: []string{"go"}, Authors: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Expected valid post, got %v", err)
+	}
+
+	// A nil slice is stored as null, so the bounds don't apply
+	if err := s.ValidateDocument(&Post{}); err != nil {
+		t.Errorf("Expected post without arrays to be valid, got %v", err)
+	}
+
+	err := s.ValidateDocument(&Post{Tags: []string{}, Authors: []string{"a", "b", "c"}})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok || len(validationErr.Errors) != 2 {
+		t.Fatalf("Expected 2 failures, got %v", err)
+	}
+	if validationErr.Errors[0].Rule != "maxItems" || validationErr.Errors[1].Rule != "minItems" {
+		t.Errorf("Unexpected failures %+v", validationErr.Errors)
+	}
+
+	// Arrays in map documents are bounded too
+	err = s.ValidateDocument(bson.M{"tags": bson.A{"a", "b", "c", "d"}})
+	if validationErr, ok := errors.AsValidationError(err); !ok || validationErr.Errors[0].Rule != "maxItems" {
+		t.Errorf("Expected maxItems failure, got %v", err)
+	}
+}
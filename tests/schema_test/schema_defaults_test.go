@@ -0,0 +1,154 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type Status string
+
+type Settings struct {
+	Theme string `bson:"theme"`
+}
+
+type Account struct {
+	Name     string     `bson:"name"`
+	Role     string     `bson:"role"`
+	Status   Status     `bson:"status"`
+	Credits  int64      `bson:"credits"`
+	Token    string     `bson:"token"`
+	Verified *bool      `bson:"verified"`
+	Settings Settings   `bson:"settings"`
+	Profiles []Settings `bson:"profiles"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	calls := 0
+	s := schema.New(map[string]schema.Field{
+		"role":     {Default: "user"},
+		"status":   {Default: "active"},
+		"credits":  {Default: 100},
+		"token":    {Default: func() interface{} { calls++; return "generated" }},
+		"verified": {Default: false},
+		"settings": {Schema: schema.New(map[string]schema.Field{"theme": {Default: "light"}})},
+		"profiles": {Schema: schema.New(map[string]schema.Field{"theme": {Default: "dark"}})},
+	})
+
+	doc := &Account{Name: "Alice", Role: "admin", Profiles: []Settings{{}, {Theme: "blue"}}}
+	if err := s.ApplyDefaults(doc); err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+
+	if doc.Role != "admin" {
+		t.Errorf("Expected set field to be kept, got %q", doc.Role)
+	}
+	if doc.Status != "active" {
+		t.Errorf("Expected default to be converted to the field type, got %q", doc.Status)
+	}
+	if doc.Credits != 100 {
+		t.Errorf("Expected numeric default to be converted, got %d", doc.Credits)
+	}
+	if doc.Token != "generated" || calls != 1 {
+		t.Errorf("Expected function default to be called once, got %q after %d calls", doc.Token, calls)
+	}
+	if doc.Verified == nil || *doc.Verified {
+		t.Errorf("Expected pointer field to be allocated with the default, got %v", doc.Verified)
+	}
+	if doc.Settings.Theme != "light" {
+		t.Errorf("Expected sub-document default, got %q", doc.Settings.Theme)
+	}
+	if doc.Profiles[0].Theme != "dark" || doc.Profiles[1].Theme != "blue" {
+		t.Errorf("Expected array element defaults, got %+v", doc.Profiles)
+	}
+
+	// A function default is only called for empty fields
+	if err := s.ApplyDefaults(doc); err != nil || calls != 1 {
+		t.Errorf("Expected function default not to be called again, got %d calls (err %v)", calls, err)
+	}
+}
+
+func TestApplyDefaults_Map(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"role":     {Default: "user"},
+		"status":   {Default: "active"},
+		"credits":  {Default: 100},
+		"verified": {Default: false},
+		"settings": {Schema: schema.New(map[string]schema.Field{"theme": {Default: "light"}})},
+		"profiles": {Schema: schema.New(map[string]schema.Field{"theme": {Default: "dark"}})},
+	})
+
+	doc := bson.M{
+		"name":     "Alice",
+		"Role":     "admin",
+		"status":   nil,
+		"credits":  0,
+		"settings": bson.M{},
+		"profiles": bson.A{bson.M{}, bson.M{"theme": "blue"}},
+	}
+	if err := s.ApplyDefaults(doc); err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+
+	if doc["Role"] != "admin" {
+		t.Errorf("Expected set field to be kept, got %v", doc["Role"])
+	}
+	if _, added := doc["role"]; added {
+		t.Error("Expected fields to be matched case-insensitively")
+	}
+	if doc["status"] != "active" {
+		t.Errorf("Expected nil field to get its default, got %v", doc["status"])
+	}
+	if doc["credits"] != 0 {
+		t.Errorf("Expected a present zero value to be kept, got %v", doc["credits"])
+	}
+	if doc["verified"] != false {
+		t.Errorf("Expected missing field to get its default, got %v", doc["verified"])
+	}
+	if doc["settings"].(bson.M)["theme"] != "light" {
+		t.Errorf("Expected sub-document default, got %v", doc["settings"])
+	}
+	profiles := doc["profiles"].(bson.A)
+	if profiles[0].(bson.M)["theme"] != "dark" || profiles[1].(bson.M)["theme"] != "blue" {
+		t.Errorf("Expected array element defaults, got %v", profiles)
+	}
+
+	// Defaults are converted to the element type of typed maps
+	counts := map[string]int64{}
+	if err := schema.New(map[string]schema.Field{"credits": {Default: 100}}).ApplyDefaults(&counts); err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+	if counts["credits"] != 100 {
+		t.Errorf("Expected default converted to the map element type, got %v", counts["credits"])
+	}
+
+	// A document with its defaults applied validates
+	required := schema.New(map[string]schema.Field{"role": {Required: true, Default: "user"}})
+	user := bson.M{"name": "Bob"}
+	if err := required.ApplyDefaults(user); err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+	if err := required.ValidateDocument(user); err != nil {
+		t.Errorf("Expected the document to validate with its default, got %v", err)
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"credits": {Default: "many"},
+	})
+
+	err := s.ApplyDefaults(&Account{})
+	if !errors.IsValidationError(err) {
+		t.Errorf("Expected a validation error for a mismatched default, got %v", err)
+	}
+
+	if err := s.ApplyDefaults(Account{}); !errors.IsValidationError(err) {
+		t.Errorf("Expected a validation error for a non-pointer document, got %v", err)
+	}
+	if err := s.ApplyDefaults(bson.D{}); !errors.IsValidationError(err) {
+		t.Errorf("Expected a validation error for a bson.D document, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ExportAddress struct {
+	City string `bson:"city" schema:"required"`
+	Zip  string `bson:"zip" schema:"match=^[0-9]{5}$"`
+}
+
+type ExportUser struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Email     string             `bson:"email" schema:"required,format=email"`
+	Age       int                `bson:"age" schema:"min=0,max=150"`
+	Discount  float64            `bson:"discount" schema:"max=0.5"`
+	Role      string             `bson:"role" schema:"default=user"`
+	Tags      []string           `bson:"tags" schema:"maxitems=5"`
+	Avatar    []byte             `bson:"avatar"`
+	OwnerID   primitive.ObjectID `bson:"ownerId"`
+	JoinedAt  time.Time          `bson:"joinedAt"`
+	Addresses []ExportAddress    `bson:"addresses" schema:"minitems=1"`
+}
+
+func TestExportJSONSchema(t *testing.T) {
+	s := schema.GenerateFromStruct(ExportUser{})
+	field := s.Fields["role"]
+	field.Enum = []interface{}{"user", "admin"}
+	s.Fields["role"] = field
+
+	exported := s.ExportJSONSchema(schema.ExportOptions{Title: "User", ID: "https://example.com/user.json"})
+
+	if exported["$schema"] != schema.JSONSchemaDialect || exported["$id"] != "https://example.com/user.json" ||
+		exported["title"] != "User" || exported["type"] != "object" {
+		t.Errorf("Unexpected document header %v", exported)
+	}
+	if required := exported["required"]; !reflect.DeepEqual(required, []string{"email"}) {
+		t.Errorf("Unexpected required fields %v", required)
+	}
+
+	properties := exported["properties"].(map[string]interface{})
+	expected := map[string]map[string]interface{}{
+		"email":    {"type": "string", "format": "email"},
+		"age":      {"type": "integer", "minimum": 0.0, "maximum": 150.0},
+		"discount": {"type": "number", "maximum": 0.5},
+		"role":     {"type": "string", "default": "user", "enum": []interface{}{"user", "admin"}},
+		"tags":     {"type": "array", "items": map[string]interface{}{"type": "string"}, "maxItems": 5},
+		"avatar":   {"type": "string", "contentEncoding": "base64"},
+		"ownerId":  {"type": "string", "pattern": "^[0-9a-fA-F]{24}$"},
+		"joinedAt": {"type": "string", "format": "date-time"},
+		"addresses": {
+			"type":     "array",
+			"minItems": 1,
+			"items": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"city"},
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+					"zip":  map[string]interface{}{"type": "string", "pattern": "^[0-9]{5}$"},
+				},
+			},
+		},
+	}
+	for name, want := range expected {
+		if got := properties[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Property %s: expected %v, got %v", name, want, got)
+		}
+	}
+
+	if _, err := json.Marshal(exported); err != nil {
+		t.Errorf("Expected the document to marshal to JSON, got %v", err)
+	}
+}
+
+func TestExportOpenAPI(t *testing.T) {
+	s := schema.GenerateFromStruct(ExportUser{})
+	component := s.ExportOpenAPI(schema.ExportOptions{Title: "User"})
+
+	if _, exists := component["$schema"]; exists {
+		t.Error("Expected no $schema in an OpenAPI component")
+	}
+	if component["title"] != "User" || component["type"] != "object" {
+		t.Errorf("Unexpected component %v", component)
+	}
+	avatar := component["properties"].(map[string]interface{})["avatar"]
+	if !reflect.DeepEqual(avatar, map[string]interface{}{"type": "string", "format": "byte"}) {
+		t.Errorf("Expected a byte string avatar, got %v", avatar)
+	}
+}
+
+type ExportCategory struct {
+	Name     string            `bson:"name" schema:"required"`
+	Children []*ExportCategory `bson:"children"`
+	Parent   *ExportNode       `bson:"parent"`
+}
+
+type ExportNode struct {
+	Label string      `bson:"label"`
+	Next  *ExportNode `bson:"next"`
+}
+
+func TestExportRecursiveSchemas(t *testing.T) {
+	s := schema.GenerateFromStruct(ExportCategory{})
+
+	exported := s.ExportJSONSchema()
+	properties := exported["properties"].(map[string]interface{})
+	children := properties["children"].(map[string]interface{})
+	if !reflect.DeepEqual(children["items"], map[string]interface{}{"$ref": "#"}) {
+		t.Errorf("Expected children to reference the root, got %v", children)
+	}
+
+	// Nested recursive schemas are referenced through an anchor
+	parent := properties["parent"].(map[string]interface{})
+	next := parent["properties"].(map[string]interface{})["next"].(map[string]interface{})
+	if parent["$anchor"] == nil || next["$ref"] != "#"+parent["$anchor"].(string) {
+		t.Errorf("Expected next to reference the parent's anchor, got %v", parent)
+	}
+
+	component := s.ExportOpenAPI(schema.ExportOptions{Title: "Category"})
+	children = component["properties"].(map[string]interface{})["children"].(map[string]interface{})
+	if !reflect.DeepEqual(children["items"], map[string]interface{}{"$ref": "#/components/schemas/Category"}) {
+		t.Errorf("Expected children to reference the component, got %v", children)
+	}
+}
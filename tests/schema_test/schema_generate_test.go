@@ -3,6 +3,7 @@ package schema_test
 import (
 	"context"
 	"fmt"
+	"github.com/isimtekin/merhongo/errors"
 	schema2 "github.com/isimtekin/merhongo/schema"
 	"reflect"
 	"strings"
@@ -147,8 +148,8 @@ func TestGenerateFromStruct(t *testing.T) {
 		fieldName string
 		required  bool
 		unique    bool
-		min       int
-		max       int
+		min       float64
+		max       float64
 	}{
 		{"Name", true, true, 0, 0},
 		{"Age", true, false, 18, 100},
@@ -174,11 +175,11 @@ func TestGenerateFromStruct(t *testing.T) {
 		}
 
 		if field.Min != tc.min {
-			t.Errorf("Field '%s': expected Min=%d, got %d", tc.fieldName, tc.min, field.Min)
+			t.Errorf("Field '%s': expected Min=%v, got %v", tc.fieldName, tc.min, field.Min)
 		}
 
 		if field.Max != tc.max {
-			t.Errorf("Field '%s': expected Max=%d, got %d", tc.fieldName, tc.max, field.Max)
+			t.Errorf("Field '%s': expected Max=%v, got %v", tc.fieldName, tc.max, field.Max)
 		}
 	}
 
@@ -245,8 +246,8 @@ func TestGenerateAdvancedFieldTypes(t *testing.T) {
 		fieldType reflect.Kind
 		required  bool
 		unique    bool
-		min       int
-		max       int
+		min       float64
+		max       float64
 	}{
 		{"Name", reflect.String, true, true, 0, 0},
 		{"Age", reflect.Int, false, false, 18, 100},
@@ -275,11 +276,11 @@ func TestGenerateAdvancedFieldTypes(t *testing.T) {
 		}
 
 		if field.Min != tc.min {
-			t.Errorf("Field '%s': expected Min=%d, got %d", tc.fieldName, tc.min, field.Min)
+			t.Errorf("Field '%s': expected Min=%v, got %v", tc.fieldName, tc.min, field.Min)
 		}
 
 		if field.Max != tc.max {
-			t.Errorf("Field '%s': expected Max=%d, got %d", tc.fieldName, tc.max, field.Max)
+			t.Errorf("Field '%s': expected Max=%v, got %v", tc.fieldName, tc.max, field.Max)
 		}
 	}
 }
@@ -365,7 +366,7 @@ func TestAnonymousFields(t *testing.T) {
 	if !exists {
 		t.Error("Expected embedded Field2 to be included in schema")
 	} else if field2.Min != 1 || field2.Max != 10 {
-		t.Errorf("Expected embedded Field2 to maintain min=1,max=10, got min=%d,max=%d", field2.Min, field2.Max)
+		t.Errorf("Expected embedded Field2 to maintain min=1,max=10, got min=%v,max=%v", field2.Min, field2.Max)
 	}
 
 	// Anonymous primitive type should be ignored
@@ -410,7 +411,7 @@ func TestMultipleTagOptions(t *testing.T) {
 		t.Fatal("Expected 'Age' field to exist in schema")
 	}
 	if ageField.Min != 21 {
-		t.Errorf("Expected Age min to be 21 (last tag value), got %d", ageField.Min)
+		t.Errorf("Expected Age min to be 21 (last tag value), got %v", ageField.Min)
 	}
 
 	// Field with conflicting constraints
@@ -419,7 +420,7 @@ func TestMultipleTagOptions(t *testing.T) {
 		t.Fatal("Expected 'Score' field to exist in schema")
 	}
 	if scoreField.Min != 20 || scoreField.Max != 10 {
-		t.Errorf("Expected Score to have min=20, max=10; got min=%d, max=%d", scoreField.Min, scoreField.Max)
+		t.Errorf("Expected Score to have min=20, max=10; got min=%v, max=%v", scoreField.Min, scoreField.Max)
 	}
 }
 
@@ -534,7 +535,7 @@ func TestInvalidTagValues(t *testing.T) {
 	}
 
 	if ageField.Min != 0 {
-		t.Errorf("Expected invalid min tag to result in 0, got %d", ageField.Min)
+		t.Errorf("Expected invalid min tag to result in 0, got %v", ageField.Min)
 	}
 
 	maxScoreField, exists := schema.Fields["MaxScore"]
@@ -543,7 +544,7 @@ func TestInvalidTagValues(t *testing.T) {
 	}
 
 	if maxScoreField.Max != 0 {
-		t.Errorf("Expected invalid max tag to result in 0, got %d", maxScoreField.Max)
+		t.Errorf("Expected invalid max tag to result in 0, got %v", maxScoreField.Max)
 	}
 }
 
@@ -1064,7 +1065,7 @@ func TestArrayHandling(t *testing.T) {
 
 	intArrayField, _ := schema.Fields["IntArray"]
 	if intArrayField.Min != 1 {
-		t.Errorf("Expected IntArray to have Min=1, got %d", intArrayField.Min)
+		t.Errorf("Expected IntArray to have Min=1, got %v", intArrayField.Min)
 	}
 }
 
@@ -1336,3 +1337,308 @@ func TestReflectNewElementHandling(t *testing.T) {
 		t.Error("Expected Complex field to not be required")
 	}
 }
+
+// TestGenerateFromStruct_SoftDeleteTag tests the softdelete schema tag
+func TestGenerateFromStruct_SoftDeleteTag(t *testing.T) {
+	type SoftDeleteStruct struct {
+		ID        primitive.ObjectID `bson:"_id,omitempty"`
+		Name      string             `bson:"name" schema:"required"`
+		RemovedAt *time.Time         `bson:"removedAt,omitempty" schema:"softdelete"`
+	}
+
+	schema := schema2.GenerateFromStruct(SoftDeleteStruct{})
+	if !schema.SoftDelete {
+		t.Fatal("Expected softdelete tag to enable soft delete")
+	}
+
+	if schema.SoftDeleteField != "removedAt" {
+		t.Errorf("Expected soft delete field to be removedAt, got %s", schema.SoftDeleteField)
+	}
+
+	// Soft delete also propagates from embedded structs
+	type Base struct {
+		DeletedAt *time.Time `bson:"deletedAt,omitempty" schema:"softdelete"`
+	}
+	type EmbeddingStruct struct {
+		Base
+		Title string `bson:"title"`
+	}
+
+	schema = schema2.GenerateFromStruct(EmbeddingStruct{})
+	if !schema.SoftDelete || schema.SoftDeleteField != "deletedAt" {
+		t.Errorf("Expected embedded softdelete tag to enable soft delete on deletedAt, got %v/%s",
+			schema.SoftDelete, schema.SoftDeleteField)
+	}
+
+	// Structs without the tag keep soft delete disabled
+	schema = schema2.GenerateFromStruct(TestStruct{})
+	if schema.SoftDelete {
+		t.Error("Expected soft delete to be disabled without the tag")
+	}
+}
+
+// TestGenerateFromStruct_RefTag tests the ref and populate schema tags
+func TestGenerateFromStruct_RefTag(t *testing.T) {
+	type Customer struct {
+		Name string `bson:"name"`
+	}
+	type Order struct {
+		ID         primitive.ObjectID   `bson:"_id,omitempty"`
+		CustomerID primitive.ObjectID   `bson:"customerId" schema:"required,ref=Customer"`
+		ItemIDs    []primitive.ObjectID `bson:"itemIds" schema:"ref=Item"`
+		Customer   *Customer            `bson:"-" schema:"populate=customerId"`
+	}
+
+	schema := schema2.GenerateFromStruct(Order{})
+
+	customerField := schema.Fields["customerId"]
+	if customerField.Ref != "Customer" || !customerField.Required {
+		t.Errorf("Expected customerId to be a required reference to Customer, got %+v", customerField)
+	}
+
+	if schema.Fields["itemIds"].Ref != "Item" {
+		t.Errorf("Expected itemIds to reference Item, got %q", schema.Fields["itemIds"].Ref)
+	}
+
+	// Fields receiving populated documents are not part of the schema
+	if _, exists := schema.Fields["-"]; exists {
+		t.Error("Expected populate target to be left out of the schema")
+	}
+}
+
+// TestGenerateFromStruct_NestedSchemas tests schemas generated for sub-documents
+func TestGenerateFromStruct_NestedSchemas(t *testing.T) {
+	type Address struct {
+		Street string `bson:"street" schema:"required"`
+		Zip    string `bson:"zip" schema:"required"`
+	}
+	type Item struct {
+		SKU      string `bson:"sku" schema:"required"`
+		Quantity int    `bson:"quantity" schema:"min=1"`
+	}
+	type Customer struct {
+		Name      string    `bson:"name" schema:"required"`
+		Address   Address   `bson:"address"`
+		Billing   *Address  `bson:"billing"`
+		Items     []Item    `bson:"items"`
+		CreatedAt time.Time `bson:"createdAt"`
+	}
+
+	schema := schema2.GenerateFromStruct(Customer{})
+
+	addressSchema := schema.Fields["address"].Schema
+	if addressSchema == nil {
+		t.Fatal("Expected address to have a nested schema")
+	}
+	if !addressSchema.Fields["zip"].Required {
+		t.Error("Expected address.zip to be required")
+	}
+	if addressSchema.Timestamps {
+		t.Error("Expected nested schemas to have timestamps disabled")
+	}
+
+	if schema.Fields["billing"].Schema == nil {
+		t.Error("Expected pointer sub-document to have a nested schema")
+	}
+	if itemSchema := schema.Fields["items"].Schema; itemSchema == nil || itemSchema.Fields["quantity"].Min != 1 {
+		t.Error("Expected array of sub-documents to have a nested schema")
+	}
+	if schema.Fields["createdAt"].Schema != nil {
+		t.Error("Expected time.Time not to be treated as a sub-document")
+	}
+
+	tests := []struct {
+		name     string
+		doc      Customer
+		expected string
+	}{
+		{
+			name: "valid document",
+			doc: Customer{
+				Name:    "Alice",
+				Address: Address{Street: "Main St", Zip: "12345"},
+				Items:   []Item{{SKU: "A1", Quantity: 1}},
+			},
+		},
+		{
+			name:     "missing nested field",
+			doc:      Customer{Name: "Alice", Address: Address{Street: "Main St"}},
+			expected: "address.zip",
+		},
+		{
+			name: "invalid pointer sub-document",
+			doc: Customer{
+				Name:    "Alice",
+				Address: Address{Street: "Main St", Zip: "12345"},
+				Billing: &Address{Zip: "12345"},
+			},
+			expected: "billing.street",
+		},
+		{
+			name: "invalid array element",
+			doc: Customer{
+				Name:    "Alice",
+				Address: Address{Street: "Main St", Zip: "12345"},
+				Items:   []Item{{SKU: "A1", Quantity: 1}, {Quantity: 2}},
+			},
+			expected: "items.1.sku",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.ValidateDocument(&tt.doc)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "'"+tt.expected+"'") {
+				t.Errorf("Expected error mentioning '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+// TestGenerateFromStruct_RecursiveNestedSchema tests that self-referencing structs reuse their schema
+func TestGenerateFromStruct_RecursiveNestedSchema(t *testing.T) {
+	type Category struct {
+		Name     string      `bson:"name" schema:"required"`
+		Children []*Category `bson:"children"`
+	}
+
+	schema := schema2.GenerateFromStruct(Category{})
+	if schema.Fields["children"].Schema != schema {
+		t.Fatal("Expected recursive field to reference the schema being generated")
+	}
+
+	doc := Category{Name: "root", Children: []*Category{{Name: "a"}, {Children: []*Category{{Name: "c"}}}}}
+	err := schema.ValidateDocument(&doc)
+	if err == nil || !strings.Contains(err.Error(), "'children.1.name'") {
+		t.Errorf("Expected error mentioning 'children.1.name', got %v", err)
+	}
+}
+
+// TestGenerateFromStruct_DefaultTag tests the default schema tag
+func TestGenerateFromStruct_DefaultTag(t *testing.T) {
+	type Role string
+	type Member struct {
+		Role    Role     `bson:"role" schema:"default=member"`
+		Active  bool     `bson:"active" schema:"default=true"`
+		Credits int32    `bson:"credits" schema:"default=50"`
+		Ratio   *float64 `bson:"ratio" schema:"default=0.5"`
+		Invalid int      `bson:"invalid" schema:"default=abc"`
+		Tags    []string `bson:"tags" schema:"default=a"`
+	}
+
+	schema := schema2.GenerateFromStruct(Member{})
+
+	expected := map[string]interface{}{
+		"role":    Role("member"),
+		"active":  true,
+		"credits": int32(50),
+		"ratio":   0.5,
+		"invalid": nil,
+		"tags":    nil,
+	}
+	for field, value := range expected {
+		if schema.Fields[field].Default != value {
+			t.Errorf("Expected default of %s to be %#v, got %#v", field, value, schema.Fields[field].Default)
+		}
+	}
+
+	member := &Member{}
+	if err := schema.ApplyDefaults(member); err != nil {
+		t.Fatalf("ApplyDefaults failed: %v", err)
+	}
+	if member.Role != "member" || !member.Active || member.Credits != 50 || member.Ratio == nil || *member.Ratio != 0.5 {
+		t.Errorf("Expected tag defaults to be applied, got %+v", member)
+	}
+}
+
+// TestGenerateFromStruct_StringTags tests the string validation schema tags
+func TestGenerateFromStruct_StringTags(t *testing.T) {
+	type Account struct {
+		Username string `bson:"username" schema:"required,minlen=3,maxlen=20,match=^[a-z]{1,20}$,trim,lowercase"`
+		Email    string `bson:"email" schema:"format=email,unique"`
+		Code     string `bson:"code" schema:"uppercase,match=^[A-Z]+$"`
+	}
+
+	schema := schema2.GenerateFromStruct(Account{})
+
+	username := schema.Fields["username"]
+	if !username.Required || username.MinLength != 3 || username.MaxLength != 20 || !username.Trim || !username.Lowercase {
+		t.Errorf("Unexpected username field %+v", username)
+	}
+	if username.Match != "^[a-z]{1,20}$" {
+		t.Errorf("Expected pattern with a comma to be kept, got %q", username.Match)
+	}
+
+	email := schema.Fields["email"]
+	if email.Format != schema2.FormatEmail || !email.Unique {
+		t.Errorf("Unexpected email field %+v", email)
+	}
+
+	code := schema.Fields["code"]
+	if !code.Uppercase || code.Match != "^[A-Z]+$" {
+		t.Errorf("Unexpected code field %+v", code)
+	}
+
+	account := &Account{Username: "  Alice ", Email: "alice@example.com", Code: "abc"}
+	schema.ApplyTransforms(account)
+	if err := schema.ValidateDocument(account); err != nil {
+		t.Errorf("Expected transformed account to be valid, got %v", err)
+	}
+}
+
+func TestGenerateFromStruct_BoundTags(t *testing.T) {
+	type Product struct {
+		Price    float64  `bson:"price" schema:"min=0"`
+		Discount float64  `bson:"discount" schema:"min=0,max=0.5"`
+		Offset   int      `bson:"offset" schema:"min=-10,max=10"`
+		Stock    uint     `bson:"stock" schema:"max=1000"`
+		Tags     []string `bson:"tags" schema:"minitems=1,maxitems=3"`
+	}
+
+	schema := schema2.GenerateFromStruct(Product{})
+
+	price := schema.Fields["price"]
+	if !price.HasMin || price.Min != 0 || price.HasMax {
+		t.Errorf("Expected price to have a zero minimum and no maximum, got %+v", price)
+	}
+	discount := schema.Fields["discount"]
+	if discount.Max != 0.5 || !discount.HasMax {
+		t.Errorf("Expected discount to have a fractional maximum, got %+v", discount)
+	}
+	if offset := schema.Fields["offset"]; offset.Min != -10 || offset.Max != 10 {
+		t.Errorf("Expected offset to have a negative minimum, got %+v", offset)
+	}
+	if tags := schema.Fields["tags"]; tags.MinItems != 1 || tags.MaxItems != 3 {
+		t.Errorf("Expected tags to have item bounds, got %+v", tags)
+	}
+
+	if err := schema.ValidateDocument(&Product{Price: 0, Discount: 0.25, Offset: -10, Stock: 1000, Tags: []string{"a"}}); err != nil {
+		t.Errorf("Expected product on its bounds to be valid, got %v", err)
+	}
+
+	err := schema.ValidateDocument(&Product{Price: -0.01, Discount: 0.75, Offset: -11, Stock: 1001})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+	expected := []struct{ path, rule string }{
+		{"discount", "max"},
+		{"offset", "min"},
+		{"price", "min"},
+		{"stock", "max"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d failures, got %v", len(expected), validationErr.Errors)
+	}
+	for i, e := range expected {
+		if validationErr.Errors[i].Path != e.path || validationErr.Errors[i].Rule != e.rule {
+			t.Errorf("Failure %d: expected %s on %s, got %+v", i, e.rule, e.path, validationErr.Errors[i])
+		}
+	}
+}
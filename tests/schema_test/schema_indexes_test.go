@@ -0,0 +1,101 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestWithIndex(t *testing.T) {
+	ttl := int32(3600)
+	s := schema.New(map[string]schema.Field{
+		"email":     {Unique: true},
+		"username":  {Index: true},
+		"expiresAt": {Index: true},
+		"name":      {},
+	},
+		schema.WithIndex(
+			schema.Index{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+			schema.Index{Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAfterSeconds: &ttl},
+		),
+		schema.WithIndex(schema.Index{
+			Name:          "active_name",
+			Keys:          bson.D{{Key: "name", Value: 1}},
+			PartialFilter: bson.M{"active": true},
+			Collation:     &options.Collation{Locale: "en", Strength: 2},
+		}),
+	)
+
+	indexes := s.AllIndexes()
+	names := make([]string, len(indexes))
+	for i, index := range indexes {
+		names[i] = index.IndexName()
+	}
+
+	// The TTL index replaces the field's index; the other field indexes come first
+	expected := []string{"email_1", "username_1", "userId_1_createdAt_-1", "expiresAt_1", "active_name"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected indexes %v, got %v", expected, names)
+	}
+	if !indexes[0].Unique || indexes[1].Unique {
+		t.Errorf("Expected only the email index to be unique, got %+v", indexes[:2])
+	}
+	if indexes[3].ExpireAfterSeconds == nil || *indexes[3].ExpireAfterSeconds != 3600 {
+		t.Errorf("Expected TTL index, got %+v", indexes[3])
+	}
+	if indexes[4].KeyName() != "name_1" {
+		t.Errorf("Expected key name name_1, got %s", indexes[4].KeyName())
+	}
+}
+
+func TestGenerateFromStruct_IndexTags(t *testing.T) {
+	type Place struct {
+		Title     string    `bson:"title" schema:"index=text,weight=10"`
+		Body      string    `bson:"body" schema:"index=text"`
+		Location  bson.M    `bson:"location" schema:"index=2dsphere"`
+		Email     string    `bson:"email" schema:"unique,sparse"`
+		Score     int       `bson:"score" schema:"index=-1"`
+		OwnerID   string    `bson:"ownerId" schema:"index,compound=owner_date"`
+		CreatedAt time.Time `bson:"createdAt" schema:"compound=owner_date:-1"`
+		ExpiresAt time.Time `bson:"expiresAt" schema:"ttl=0"`
+	}
+
+	s := schema.GenerateFromStruct(Place{})
+	indexes := s.AllIndexes()
+
+	byName := make(map[string]schema.Index)
+	for _, index := range indexes {
+		byName[index.IndexName()] = index
+	}
+	if len(indexes) != 7 {
+		t.Fatalf("Expected 7 indexes, got %+v", indexes)
+	}
+
+	text := byName["title_text_body_text"]
+	if len(text.Keys) != 2 || text.Weights["title"] != 10 {
+		t.Errorf("Expected one text index with weights, got %+v", text)
+	}
+	if _, exists := byName["location_2dsphere"]; !exists {
+		t.Error("Expected a 2dsphere index")
+	}
+	if email := byName["email_1"]; !email.Unique || !email.Sparse {
+		t.Errorf("Expected a sparse unique email index, got %+v", email)
+	}
+	if _, exists := byName["score_-1"]; !exists {
+		t.Error("Expected a descending score index")
+	}
+	if _, exists := byName["ownerId_1"]; !exists {
+		t.Error("Expected the ownerId field index")
+	}
+	compound := byName["owner_date"]
+	if !reflect.DeepEqual(compound.Keys, bson.D{{Key: "ownerId", Value: 1}, {Key: "createdAt", Value: -1}}) {
+		t.Errorf("Unexpected compound index keys %v", compound.Keys)
+	}
+	if ttl := byName["expiresAt_1"]; ttl.ExpireAfterSeconds == nil || *ttl.ExpireAfterSeconds != 0 {
+		t.Errorf("Expected a TTL index expiring at the stored date, got %+v", ttl)
+	}
+}
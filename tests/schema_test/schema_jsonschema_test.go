@@ -0,0 +1,191 @@
+package schema_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	address := schema.New(map[string]schema.Field{
+		"city": {Type: "", Required: true},
+		"zip":  {Type: "", Match: "^[0-9]{5}$"},
+	}, schema.WithTimestamps(false))
+	item := schema.New(map[string]schema.Field{
+		"sku": {Type: "", Required: true},
+		"qty": {Type: 0, Min: 1},
+	}, schema.WithTimestamps(false))
+
+	s := schema.New(map[string]schema.Field{
+		"username": {Type: "", Required: true, MinLength: 3, MaxLength: 20},
+		"age":      {Type: 0, Min: 0, Max: 150, HasMin: true},
+		"price":    {Type: 0.0, Max: 0.5},
+		"role":     {Type: "", Enum: []interface{}{"user", "admin"}},
+		"active":   {Type: true},
+		"joinedAt": {Type: time.Time{}, Required: true},
+		"ownerId":  {Type: primitive.ObjectID{}},
+		"tags":     {Type: []string{}, MinItems: 1, MaxItems: 5},
+		"address":  {Type: map[string]interface{}{}, Schema: address},
+		"items":    {Type: []interface{}{}, Schema: item, Required: true},
+		"anything": {},
+	})
+
+	jsonSchema := s.ToJSONSchema()
+	if jsonSchema["bsonType"] != "object" {
+		t.Errorf("Expected an object schema, got %v", jsonSchema["bsonType"])
+	}
+	if required := jsonSchema["required"]; !reflect.DeepEqual(required, []string{"items", "joinedAt", "username"}) {
+		t.Errorf("Unexpected required fields %v", required)
+	}
+
+	properties := jsonSchema["properties"].(bson.M)
+	expected := map[string]bson.M{
+		"username": {"bsonType": "string", "minLength": 3, "maxLength": 20},
+		"age":      {"bsonType": []string{"int", "long", "double", "null"}, "multipleOf": 1, "minimum": 0.0, "maximum": 150.0},
+		"price":    {"bsonType": []string{"number", "null"}, "maximum": 0.5},
+		"role":     {"bsonType": []string{"string", "null"}, "enum": bson.A{"user", "admin", nil}},
+		"active":   {"bsonType": []string{"bool", "null"}},
+		"joinedAt": {"bsonType": "date"},
+		"ownerId":  {"bsonType": []string{"objectId", "null"}},
+		"tags": {
+			"bsonType": []string{"array", "null"}, "minItems": 1, "maxItems": 5,
+			"items": bson.M{"bsonType": "string"},
+		},
+		"address": {
+			"bsonType":   []string{"object", "null"},
+			"required":   []string{"city"},
+			"properties": bson.M{"city": bson.M{"bsonType": "string"}, "zip": bson.M{"bsonType": []string{"string", "null"}, "pattern": "^[0-9]{5}$"}},
+		},
+		"items": {
+			"bsonType": "array",
+			"items": bson.M{
+				"bsonType":   "object",
+				"required":   []string{"sku"},
+				"properties": bson.M{"sku": bson.M{"bsonType": "string"}, "qty": bson.M{"bsonType": []string{"int", "long", "double", "null"}, "multipleOf": 1, "minimum": 1.0}},
+			},
+		},
+		"anything": {},
+	}
+	for name, want := range expected {
+		if got := properties[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Property %s: expected %v, got %v", name, want, got)
+		}
+	}
+
+	// Custom validators can't be expressed in $jsonSchema
+	s.CustomValidator = func(doc interface{}) error { return nil }
+	if got := s.ToJSONSchema(); !reflect.DeepEqual(got, bson.M{"bsonType": "object"}) {
+		t.Errorf("Expected an unconstrained object, got %v", got)
+	}
+}
+
+// storedBSONType returns the $jsonSchema alias of the BSON type a value is stored as
+func storedBSONType(t *testing.T, value interface{}) string {
+	raw, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		t.Fatalf("Failed to marshal %v: %v", value, err)
+	}
+	switch bson.Raw(raw).Lookup("v").Type {
+	case bsontype.Int32:
+		return "int"
+	case bsontype.Int64:
+		return "long"
+	case bsontype.Double:
+		return "double"
+	case bsontype.String:
+		return "string"
+	}
+	return ""
+}
+
+func TestToJSONSchema_MatchesValidateDocument(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"stock": {Type: 0, Required: true},
+	}, schema.WithTimestamps(false))
+	property := s.ToJSONSchema()["properties"].(bson.M)["stock"].(bson.M)
+	types := property["bsonType"].([]string)
+
+	// The server validator accepts the map documents ValidateDocument accepts, and
+	// rejects the ones it rejects
+	tests := []struct {
+		name  string
+		value interface{}
+		valid bool
+	}{
+		{"int", 3, true},
+		{"long", int64(3), true},
+		{"whole double", 3.0, true},
+		{"fractional double", 2.5, false},
+		{"string", "3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateDocument(bson.M{"stock": tt.value})
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateDocument: expected valid=%v, got %v", tt.valid, err)
+			}
+
+			stored := storedBSONType(t, tt.value)
+			accepted := false
+			for _, bsonType := range types {
+				accepted = accepted || bsonType == stored
+			}
+			if f, ok := tt.value.(float64); ok && accepted {
+				accepted = math.Mod(f, float64(property["multipleOf"].(int))) == 0
+			}
+			if accepted != tt.valid {
+				t.Errorf("$jsonSchema %v: expected valid=%v for a %s", property, tt.valid, stored)
+			}
+		})
+	}
+}
+
+func TestToJSONSchema_StoredNames(t *testing.T) {
+	type Customer struct {
+		ID        primitive.ObjectID `bson:"_id,omitempty"`
+		FullName  string             `bson:"full_name"`
+		Email     string
+		Addresses []struct {
+			Street string `bson:"street"`
+		} `bson:"addresses"`
+	}
+
+	street := schema.New(map[string]schema.Field{"Street": {Required: true}}, schema.WithTimestamps(false))
+	s := schema.New(map[string]schema.Field{
+		"Full_Name": {Required: true},
+		"Email":     {Required: true},
+		"addresses": {Schema: street},
+	}, schema.WithModelType(&Customer{}))
+
+	jsonSchema := s.ToJSONSchema()
+	if required := jsonSchema["required"]; !reflect.DeepEqual(required, []string{"email", "full_name"}) {
+		t.Errorf("Expected the stored names to be required, got %v", required)
+	}
+
+	// Types come from the struct fields when the schema fields have none
+	addresses := jsonSchema["properties"].(bson.M)["addresses"].(bson.M)
+	if addresses["bsonType"] == nil || !reflect.DeepEqual(addresses["items"].(bson.M)["required"], []string{"street"}) {
+		t.Errorf("Unexpected addresses property %v", addresses)
+	}
+}
+
+func TestToJSONSchema_RecursiveSchema(t *testing.T) {
+	type Category struct {
+		Name     string      `bson:"name" schema:"required"`
+		Children []*Category `bson:"children"`
+	}
+
+	jsonSchema := schema.GenerateFromStruct(Category{}).ToJSONSchema()
+
+	// The recursive field describes its documents without their properties
+	children := jsonSchema["properties"].(bson.M)["children"].(bson.M)
+	if !reflect.DeepEqual(children["items"], bson.M{"bsonType": "object"}) {
+		t.Errorf("Expected the recursion to stop at the children, got %v", children)
+	}
+}
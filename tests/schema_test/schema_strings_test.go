@@ -0,0 +1,135 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/isimtekin/merhongo/errors"
+	"github.com/isimtekin/merhongo/schema"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStringRules(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"username": {MinLength: 3, MaxLength: 8, Match: "^[a-z_]+$"},
+		"email":    {Format: schema.FormatEmail},
+		"website":  {Format: schema.FormatURL},
+		"token":    {Format: schema.FormatUUID},
+		"ownerId":  {Format: schema.FormatObjectID},
+		"birthday": {Format: schema.FormatDate},
+		"loginAt":  {Format: schema.FormatDateTime},
+	})
+
+	valid := bson.M{
+		"username": "jöhn_doe",
+		"email":    "john@example.com",
+		"website":  "https://example.com/john",
+		"token":    "123e4567-e89b-12d3-a456-426614174000",
+		"ownerId":  "507f1f77bcf86cd799439011",
+		"birthday": "1990-04-01",
+		"loginAt":  "2024-01-02T15:04:05Z",
+	}
+	// Lengths count characters, not bytes, but the pattern rejects "ö"
+	err := s.ValidateDocument(valid)
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok || len(validationErr.Errors) != 1 || validationErr.Errors[0].Rule != "match" {
+		t.Fatalf("Expected only a pattern failure, got %v", err)
+	}
+	valid["username"] = "john_doe"
+	if err := s.ValidateDocument(valid); err != nil {
+		t.Fatalf("Expected valid document, got %v", err)
+	}
+
+	err = s.ValidateDocument(bson.M{
+		"username": "jo",
+		"email":    "john@",
+		"website":  "example.com",
+		"token":    "123e4567",
+		"ownerId":  "xyz",
+		"birthday": "01/04/1990",
+		"loginAt":  "2024-01-02",
+	})
+	validationErr, ok = errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+
+	expected := []struct{ path, rule string }{
+		{"birthday", "format"},
+		{"email", "format"},
+		{"loginAt", "format"},
+		{"ownerId", "format"},
+		{"token", "format"},
+		{"username", "minLength"},
+		{"website", "format"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d failures, got %v", len(expected), validationErr.Errors)
+	}
+	for i, e := range expected {
+		if fieldErr := validationErr.Errors[i]; fieldErr.Path != e.path || fieldErr.Rule != e.rule {
+			t.Errorf("Expected failure %d to be %s/%s, got %+v", i, e.path, e.rule, fieldErr)
+		}
+	}
+
+	if err := s.ValidateDocument(bson.M{"username": "much_too_long"}); err == nil ||
+		err.Error() != "validation failed: field 'username' length 13 is greater than maximum length 8" {
+		t.Errorf("Unexpected max length error: %v", err)
+	}
+}
+
+func TestStringRulesMisconfigured(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"code": {Match: "[a-z"},
+		"kind": {Format: "color"},
+	})
+
+	err := s.ValidateDocument(bson.M{"code": "abc", "kind": "red"})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok || len(validationErr.Errors) != 2 {
+		t.Fatalf("Expected pattern and format errors, got %v", err)
+	}
+	if validationErr.Errors[1].Message != "field 'kind' has unknown format 'color'" {
+		t.Errorf("Unexpected message %q", validationErr.Errors[1].Message)
+	}
+}
+
+func TestApplyTransforms(t *testing.T) {
+	type Profile struct {
+		Country string `bson:"country"`
+	}
+	type Member struct {
+		Email   string      `bson:"email"`
+		Code    *string     `bson:"code"`
+		Nick    interface{} `bson:"nick"`
+		Profile Profile     `bson:"profile"`
+	}
+
+	s := schema.New(map[string]schema.Field{
+		"email": {Trim: true, Lowercase: true},
+		"code":  {Uppercase: true},
+		"nick":  {Trim: true},
+		"profile": {Schema: schema.New(map[string]schema.Field{
+			"country": {Uppercase: true},
+		})},
+	})
+
+	code := "ab1"
+	member := &Member{Email: "  John@Example.COM ", Code: &code, Nick: " johnny ", Profile: Profile{Country: "de"}}
+	s.ApplyTransforms(member)
+	if member.Email != "john@example.com" || *member.Code != "AB1" || member.Nick != "johnny" || member.Profile.Country != "DE" {
+		t.Errorf("Unexpected transformed struct %+v", member)
+	}
+
+	doc := bson.M{"email": " Jane@Example.com", "profile": bson.M{"country": "fr"}, "other": " kept "}
+	s.ApplyTransforms(doc)
+	if doc["email"] != "jane@example.com" || doc["profile"].(bson.M)["country"] != "FR" || doc["other"] != " kept " {
+		t.Errorf("Unexpected transformed map %v", doc)
+	}
+
+	update := bson.M{"$set": bson.M{"email": " A@B.COM ", "profile.country": "it"}, "$inc": bson.M{"logins": 1}}
+	s.TransformUpdate(update)
+	set := update["$set"].(bson.M)
+	if set["email"] != "a@b.com" || set["profile.country"] != "IT" {
+		t.Errorf("Unexpected transformed update %v", update)
+	}
+}
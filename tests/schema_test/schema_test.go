@@ -1,8 +1,12 @@
 package schema_test
 
 import (
+	"github.com/isimtekin/merhongo/errors"
 	"github.com/isimtekin/merhongo/schema"
+	"strings"
 	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestSchemaCreation(t *testing.T) {
@@ -60,3 +64,344 @@ func TestPreMiddlewareRegistration(t *testing.T) {
 		t.Error("middleware function was not executed properly")
 	}
 }
+
+func TestPostMiddlewareRegistration(t *testing.T) {
+	s := schema.New(map[string]schema.Field{})
+
+	var received interface{}
+	s.Post("save", func(doc interface{}) error {
+		received = doc
+		return nil
+	})
+
+	if len(s.PostMiddlewares["save"]) != 1 {
+		t.Fatal("expected one post-save middleware to be registered")
+	}
+
+	if len(s.Middlewares["save"]) != 0 {
+		t.Error("expected post middleware not to be registered as a pre middleware")
+	}
+
+	err := s.PostMiddlewares["save"][0]("doc")
+	if err != nil || received != "doc" {
+		t.Error("post middleware function was not executed properly")
+	}
+
+	// Registering on a schema built without New should not panic
+	literal := &schema.Schema{}
+	literal.Post("delete", func(doc interface{}) error { return nil })
+	if len(literal.PostMiddlewares["delete"]) != 1 {
+		t.Error("expected post middleware to be registered on literal schema")
+	}
+}
+
+func TestHookRegistration(t *testing.T) {
+	s := schema.New(map[string]schema.Field{})
+
+	s.PreHook("find", func(hc *schema.HookContext) error {
+		hc.Filter["tenantId"] = "acme"
+		return nil
+	})
+	s.PostHook("save", func(hc *schema.HookContext) error {
+		return nil
+	})
+
+	if len(s.PreHooks["find"]) != 1 {
+		t.Fatal("expected one pre-find hook to be registered")
+	}
+
+	if len(s.PostHooks["save"]) != 1 {
+		t.Error("expected one post-save hook to be registered")
+	}
+
+	// The legacy middleware map keeps working alongside hooks
+	if len(s.Middlewares) != 0 {
+		t.Error("expected hooks not to be registered as legacy middlewares")
+	}
+
+	hc := &schema.HookContext{Filter: map[string]interface{}{"name": "john"}}
+	if err := s.PreHooks["find"][0](hc); err != nil {
+		t.Fatalf("unexpected hook error: %v", err)
+	}
+
+	if hc.Filter["tenantId"] != "acme" {
+		t.Errorf("expected hook to modify the filter, got %v", hc.Filter)
+	}
+}
+
+func TestWithSoftDeleteOption(t *testing.T) {
+	s := schema.New(map[string]schema.Field{})
+	if s.SoftDelete {
+		t.Error("expected soft delete to be disabled by default")
+	}
+
+	s = schema.New(map[string]schema.Field{}, schema.WithSoftDelete())
+	if !s.SoftDelete {
+		t.Error("expected soft delete to be enabled")
+	}
+
+	if s.SoftDeleteField != schema.DefaultSoftDeleteField {
+		t.Errorf("expected soft delete field %q, got %q", schema.DefaultSoftDeleteField, s.SoftDeleteField)
+	}
+}
+
+func TestWithVersionKeyOption(t *testing.T) {
+	s := schema.New(map[string]schema.Field{})
+	if s.VersionKey != "" {
+		t.Errorf("expected no version key by default, got %q", s.VersionKey)
+	}
+
+	s = schema.New(map[string]schema.Field{}, schema.WithVersionKey("__v"))
+	if s.VersionKey != "__v" {
+		t.Errorf("expected version key __v, got %q", s.VersionKey)
+	}
+}
+
+func TestValidateDocumentReportsAllFailures(t *testing.T) {
+	type Address struct {
+		Zip string `bson:"zip"`
+	}
+	type User struct {
+		Name    string  `bson:"name"`
+		Age     int     `bson:"age"`
+		Role    string  `bson:"role"`
+		Address Address `bson:"address"`
+	}
+
+	s := schema.New(map[string]schema.Field{
+		"name": {Required: true},
+		"age":  {Min: 18, Max: 100},
+		"role": {Enum: []interface{}{"user", "admin"}},
+		"address": {Schema: schema.New(map[string]schema.Field{
+			"zip": {Required: true},
+		})},
+	})
+
+	err := s.ValidateDocument(&User{Age: 12, Role: "guest"})
+	if !errors.IsValidationError(err) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %T", err)
+	}
+
+	expected := []struct {
+		path  string
+		rule  string
+		value interface{}
+	}{
+		{"address.zip", "required", ""},
+		{"age", "min", int64(12)},
+		{"name", "required", ""},
+		{"role", "enum", "guest"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d failures, got %v", len(expected), validationErr.Errors)
+	}
+	for i, e := range expected {
+		fieldErr := validationErr.Errors[i]
+		if fieldErr.Path != e.path || fieldErr.Rule != e.rule || fieldErr.Value != e.value || fieldErr.Message == "" {
+			t.Errorf("Expected failure %d to be %s/%s/%v, got %+v", i, e.path, e.rule, e.value, fieldErr)
+		}
+	}
+
+	if err := s.ValidateDocument(&User{Name: "Alice", Age: 30, Role: "user", Address: Address{Zip: "12345"}}); err != nil {
+		t.Errorf("Expected valid document to pass, got %v", err)
+	}
+}
+
+func TestValidateDocumentTypes(t *testing.T) {
+	type Entry struct {
+		Name  string      `bson:"name"`
+		Count int64       `bson:"count"`
+		Value interface{} `bson:"value"`
+		Tags  interface{} `bson:"tags"`
+	}
+
+	s := schema.New(map[string]schema.Field{
+		"name":  {Type: ""},
+		"count": {Type: 0, Min: 1},
+		"value": {Type: 0, Max: 10},
+		"tags":  {Type: []string{}},
+	})
+
+	valid := []Entry{
+		{Name: "a", Count: 1, Value: 5, Tags: []string{"x"}},
+		{Name: "b", Count: 1, Value: float64(3), Tags: []interface{}{"x"}},
+		{Name: "c", Count: 1, Value: int32(7)},
+	}
+	for _, doc := range valid {
+		if err := s.ValidateDocument(&doc); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", doc, err)
+		}
+	}
+
+	err := s.ValidateDocument(&Entry{Name: "d", Count: 1, Value: "five", Tags: "x"})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+	if len(validationErr.Errors) != 2 {
+		t.Fatalf("Expected 2 type errors, got %v", validationErr.Errors)
+	}
+	for _, fieldErr := range validationErr.Errors {
+		if fieldErr.Rule != "type" {
+			t.Errorf("Expected a type error, got %+v", fieldErr)
+		}
+	}
+	if validationErr.Errors[1].Message != "field 'value' has type string, expected int" {
+		t.Errorf("Unexpected message %q", validationErr.Errors[1].Message)
+	}
+
+	// Values of interface fields are checked against the other rules too
+	err = s.ValidateDocument(&Entry{Name: "e", Count: 1, Value: 11})
+	if err == nil || !strings.Contains(err.Error(), "greater than maximum") {
+		t.Errorf("Expected max error for interface field, got %v", err)
+	}
+
+	// Fractional numbers don't match integer fields
+	err = s.ValidateDocument(&Entry{Name: "f", Count: 1, Value: 2.5})
+	if err == nil || !strings.Contains(err.Error(), "has type float64, expected int") {
+		t.Errorf("Expected type error for fractional number, got %v", err)
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"age":  {Type: 0},
+		"name": {Type: ""},
+		"items": {Schema: schema.New(map[string]schema.Field{
+			"quantity": {Type: 0},
+		})},
+	})
+
+	valid := []bson.M{
+		{"$set": bson.M{"age": 30, "name": "Alice"}},
+		{"$set": bson.M{"items.0.quantity": 2, "unknown": "value"}},
+		{"$inc": bson.M{"age": 1}},
+		{"name": "Bob", "age": int64(40)},
+	}
+	for _, update := range valid {
+		if err := s.ValidateUpdate(update); err != nil {
+			t.Errorf("Expected %v to be valid, got %v", update, err)
+		}
+	}
+
+	err := s.ValidateUpdate(bson.M{
+		"$set":         bson.M{"age": "thirty"},
+		"$setOnInsert": bson.M{"items.$.quantity": "two"},
+	})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+	if paths := validationErr.Paths(); len(paths) != 2 || paths[0] != "age" || paths[1] != "items.$.quantity" {
+		t.Errorf("Unexpected failed paths %v", paths)
+	}
+}
+
+func TestValidateUpdate_Positional(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"items": {Schema: schema.New(map[string]schema.Field{
+			"sku":      {Type: "", Required: true},
+			"quantity": {Type: 0, Min: 1, HasMin: true},
+		})},
+	})
+
+	valid := []bson.M{
+		{"$set": bson.M{"items.$.quantity": 2}},
+		{"$set": bson.M{"items.$[].quantity": 3}},
+		{"$set": bson.M{"items.$[elem].quantity": 4}},
+		{"$set": bson.M{"items.$": bson.M{"sku": "A1", "quantity": 1}}},
+	}
+	for _, update := range valid {
+		if err := s.ValidateUpdate(update); err != nil {
+			t.Errorf("Expected %v to be valid, got %v", update, err)
+		}
+	}
+
+	// Values set through a positional operator are checked against every rule
+	invalid := []bson.M{
+		{"$set": bson.M{"items.$.quantity": 0}},
+		{"$set": bson.M{"items.$[elem].quantity": -1}},
+		{"$set": bson.M{"items.$": bson.M{"quantity": 1}}},
+	}
+	for _, update := range invalid {
+		if err := s.ValidateUpdate(update); !errors.IsValidationError(err) {
+			t.Errorf("Expected %v to be invalid, got %v", update, err)
+		}
+	}
+}
+
+func TestValidateMapDocuments(t *testing.T) {
+	s := schema.New(map[string]schema.Field{
+		"name": {Required: true, Type: ""},
+		"age":  {Type: 0, Min: 18},
+		"role": {Enum: []interface{}{"user", "admin"}},
+		"email": {ValidateFunc: func(v interface{}) bool {
+			email, ok := v.(string)
+			return ok && strings.Contains(email, "@")
+		}},
+		"address": {Schema: schema.New(map[string]schema.Field{
+			"zip": {Required: true},
+		})},
+		"items": {Schema: schema.New(map[string]schema.Field{
+			"sku": {Required: true},
+		})},
+	})
+
+	valid := []interface{}{
+		bson.M{"name": "Alice", "age": 30, "role": "user", "email": "alice@example.com"},
+		map[string]interface{}{"name": "Bob", "address": map[string]interface{}{"zip": "12345"}},
+		&bson.M{"name": "Carol", "items": bson.A{bson.M{"sku": "A1"}}},
+		bson.D{{Key: "name", Value: "Dave"}, {Key: "age", Value: float64(40)}},
+		map[string]string{"name": "Eve"},
+		bson.M{"Name": "Frank"},
+	}
+	for _, doc := range valid {
+		if err := s.ValidateDocument(doc); err != nil {
+			t.Errorf("Expected %v to be valid, got %v", doc, err)
+		}
+	}
+
+	err := s.ValidateDocument(bson.M{
+		"age":     "old",
+		"role":    "guest",
+		"email":   "invalid",
+		"address": bson.M{"zip": nil},
+		"items":   bson.A{bson.M{"sku": "A1"}, bson.D{{Key: "quantity", Value: 2}}},
+	})
+	validationErr, ok := errors.AsValidationError(err)
+	if !ok {
+		t.Fatalf("Expected a structured validation error, got %v", err)
+	}
+
+	expected := []struct{ path, rule string }{
+		{"address.zip", "required"},
+		{"age", "type"},
+		{"email", "custom"},
+		{"items.1.sku", "required"},
+		{"name", "required"},
+		{"role", "enum"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d failures, got %v", len(expected), validationErr.Errors)
+	}
+	for i, e := range expected {
+		if fieldErr := validationErr.Errors[i]; fieldErr.Path != e.path || fieldErr.Rule != e.rule {
+			t.Errorf("Expected failure %d to be %s/%s, got %+v", i, e.path, e.rule, fieldErr)
+		}
+	}
+
+	// Empty values of required map fields are reported like struct fields
+	err = s.ValidateDocument(bson.M{"name": ""})
+	if err == nil || !strings.Contains(err.Error(), "required field 'name' is empty") {
+		t.Errorf("Expected empty required field error, got %v", err)
+	}
+
+	if err := s.ValidateDocument([]string{"not", "a", "document"}); !errors.IsValidationError(err) {
+		t.Errorf("Expected validation error for a non-document, got %v", err)
+	}
+}